@@ -7,22 +7,25 @@ import (
 
 // Circular movement
 type ArcMovement struct {
-	start_position  Vector3d
-	end_position    Vector3d
+	start_position  AxisPosition
+	end_position    AxisPosition
 	start_velocity  float64
 	end_velocity    float64
 	target_velocity float64
 	gcodeVelocity   float64
-	clockwise       bool
-	center_offset   Vector3d
-	axis            Axis
+
+	// The auxiliary axes of the end follow the start unless they are set
+	auxiliary_motion bool
+	clockwise        bool
+	center_offset    Vector3d
+	axis             Axis
 }
 
 // Create a new circular movement
 func newArcMovement(end_position Vector3d, center_offset Vector3d, gcodeVelocity float64, clockwise bool, axis Axis) *ArcMovement {
 	return &ArcMovement{
-		start_position:  Vector3d{X: 0, Y: 0, Z: 0},
-		end_position:    end_position,
+		start_position:  newAxisPosition(Vector3d{X: 0, Y: 0, Z: 0}),
+		end_position:    newAxisPosition(end_position),
 		start_velocity:  gcodeVelocity,
 		target_velocity: gcodeVelocity,
 		gcodeVelocity:   gcodeVelocity,
@@ -35,12 +38,12 @@ func newArcMovement(end_position Vector3d, center_offset Vector3d, gcodeVelocity
 
 // Get the start position
 func (m *ArcMovement) getStartPosition() Vector3d {
-	return m.start_position
+	return m.start_position.cartesian()
 }
 
 // Get the end position
 func (m *ArcMovement) getEndPosition() Vector3d {
-	return m.end_position
+	return m.end_position.cartesian()
 }
 
 // Get the start velocity
@@ -60,12 +63,36 @@ func (m *ArcMovement) getTargetVelocity() float64 {
 
 // Set the start position
 func (m *ArcMovement) setStartPosition(start_position Vector3d) {
-	m.start_position = start_position
+	m.start_position = m.start_position.withCartesian(start_position)
 }
 
 // Set the end position
 func (m *ArcMovement) setEndPosition(end_position Vector3d) {
+	m.end_position = m.end_position.withCartesian(end_position)
+}
+
+// Get the start position of all the axes
+func (m *ArcMovement) getStartAxisPosition() AxisPosition {
+	return m.start_position
+}
+
+// Get the end position of all the axes
+func (m *ArcMovement) getEndAxisPosition() AxisPosition {
+	return m.end_position
+}
+
+// Set the start position of all the axes
+func (m *ArcMovement) setStartAxisPosition(start_position AxisPosition) {
+	m.start_position = start_position
+	if !m.auxiliary_motion {
+		m.end_position = m.end_position.withAuxiliary(start_position)
+	}
+}
+
+// Set the end position of all the axes
+func (m *ArcMovement) setEndAxisPosition(end_position AxisPosition) {
 	m.end_position = end_position
+	m.auxiliary_motion = true
 }
 
 // Set the start velocity
@@ -91,26 +118,31 @@ func (m *ArcMovement) setGcodeVelocity(gcodeVelocity float64) {
 	m.gcodeVelocity = gcodeVelocity
 }
 
-// Get the start direction
+// Get the start direction, tangent to the arc in the direction of travel
 func (m *ArcMovement) getStartDirection() Vector3d {
-	return m.center_offset.Rotate90(m.axis, !m.clockwise)
+	return m.center_offset.project(m.axis).normalize().Rotate90(m.axis, m.clockwise)
 }
 
 // Get the center
 func (m *ArcMovement) getCenter() Vector3d {
-	return m.start_position.Add(m.center_offset)
+	return m.getStartPosition().Add(m.center_offset)
 }
 
-// Get the end direction
+// Get the end direction, tangent to the arc pointing back along the path
 func (m *ArcMovement) getEndDirection() Vector3d {
-	return m.end_position.subtract(m.getCenter()).normalize().Rotate90(m.axis, m.clockwise)
+	return m.getEndPosition().subtract(m.getCenter()).project(m.axis).normalize().Rotate90(m.axis, m.clockwise)
 }
 
-// Get the angle of the movement
+// Get the angle swept by the movement
 func (m *ArcMovement) angle() float64 {
-	angle := m.getStartDirection().AngleWith(m.getEndDirection())
+	start := m.getStartPosition().subtract(m.getCenter()).project(m.axis)
+	end := m.getEndPosition().subtract(m.getCenter()).project(m.axis)
 
-	if m.clockwise {
+	angle := start.AngleWith(end)
+
+	// Going the other way around the axis covers the rest of the circle
+	counterClockwise := start.Cross(end).component(m.axis) > 0
+	if counterClockwise == m.clockwise || angle == 0 {
 		angle = 2*math.Pi - angle
 	}
 
@@ -167,7 +199,57 @@ func (m *ArcMovement) getLength() float64 {
 	return m.center_offset.length() * m.angle()
 }
 
+// Get the position at a fraction of the movement, from 0 at the start to 1 at the end
+func (m *ArcMovement) getPositionAt(ratio float64) Vector3d {
+	angle := m.angle() * ratio
+	if m.clockwise {
+		angle = -angle
+	}
+
+	// Rotate in the plane of the arc and move linearly along the axis for helixes
+	radial := m.getStartPosition().subtract(m.getCenter()).project(m.axis).rotate(m.axis, angle)
+	helix := m.getEndPosition().subtract(m.getStartPosition()).scale(ratio)
+	position := m.getCenter().project(m.axis).Add(radial)
+
+	switch m.axis {
+	case XAxis:
+		position.X = m.getStartPosition().X + helix.X
+	case YAxis:
+		position.Y = m.getStartPosition().Y + helix.Y
+	case ZAxis:
+		position.Z = m.getStartPosition().Z + helix.Z
+	}
+
+	return position
+}
+
+// Get the position of all the axes at a fraction of the movement, the ends are
+// kept as they are so that the end of a full circle stays on its start
+func (m *ArcMovement) getAxisPositionAt(ratio float64) AxisPosition {
+	switch ratio {
+	case 0:
+		return m.start_position
+	case 1:
+		return m.end_position
+	}
+	return m.start_position.interpolate(m.end_position, ratio).withCartesian(m.getPositionAt(ratio))
+}
+
+// Get the part of the movement between two fractions of it, as a copy around
+// the same center keeping the velocities of the movement
+func (m *ArcMovement) getSegment(from float64, to float64) Movement {
+	segment := *m
+	segment.start_position = m.getAxisPositionAt(from)
+	segment.end_position = m.getAxisPositionAt(to)
+	segment.center_offset = m.getCenter().subtract(segment.getStartPosition()).project(m.axis)
+	return &segment
+}
+
 // Return a string representation of the movement
 func (m *ArcMovement) String() string {
-	return fmt.Sprintf("Arc move:    Pos: %7.3f -> %7.3f  Velocity: %7.3f m/s -> %7.3f m/s -> %7.3f m/s", m.start_position, m.end_position, m.start_velocity, m.target_velocity, m.end_velocity)
+	description := fmt.Sprintf("Arc move:    Pos: %7.3f -> %7.3f  Velocity: %7.3f m/s -> %7.3f m/s -> %7.3f m/s", m.getStartPosition(), m.getEndPosition(), m.start_velocity, m.target_velocity, m.end_velocity)
+	if !m.start_position.sameAuxiliary(m.end_position) {
+		description += fmt.Sprintf("  Axes: %v -> %v", m.start_position, m.end_position)
+	}
+	return description
 }
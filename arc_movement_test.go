@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Check that two values are equal up to the rounding errors
+func checkFloat(t *testing.T, name string, actual float64, expected float64) {
+	t.Helper()
+	if math.Abs(actual-expected) > 1e-9 {
+		t.Errorf("%s: got %g, expected %g", name, actual, expected)
+	}
+}
+
+// Check that two vectors are equal up to the rounding errors
+func checkVector(t *testing.T, name string, actual Vector3d, expected Vector3d) {
+	t.Helper()
+	if actual.subtract(expected).length() > 1e-9 {
+		t.Errorf("%s: got %v, expected %v", name, actual, expected)
+	}
+}
+
+// Create an arc from a start position
+func newTestArc(start Vector3d, end Vector3d, centerOffset Vector3d, clockwise bool, axis Axis) *ArcMovement {
+	arc := newArcMovement(end, centerOffset, 0, clockwise, axis)
+	arc.setStartPosition(start)
+	return arc
+}
+
+func TestArcClockwise(t *testing.T) {
+	// From the left of the center to above it, a quarter turn
+	arc := newTestArc(Vector3d{}, Vector3d{X: 1, Y: 1}, Vector3d{X: 1}, true, ZAxis)
+
+	checkVector(t, "start direction", arc.getStartDirection(), Vector3d{Y: 1})
+	checkVector(t, "end direction", arc.getEndDirection(), Vector3d{X: -1})
+	checkFloat(t, "angle", arc.angle(), math.Pi/2)
+	checkFloat(t, "length", arc.getLength(), math.Pi/2)
+}
+
+func TestArcCounterClockwise(t *testing.T) {
+	// The same end points the other way around, three quarter turns
+	arc := newTestArc(Vector3d{}, Vector3d{X: 1, Y: 1}, Vector3d{X: 1}, false, ZAxis)
+
+	checkVector(t, "start direction", arc.getStartDirection(), Vector3d{Y: -1})
+	checkVector(t, "end direction", arc.getEndDirection(), Vector3d{X: 1})
+	checkFloat(t, "angle", arc.angle(), 3*math.Pi/2)
+}
+
+func TestArcHalfCircle(t *testing.T) {
+	for _, clockwise := range []bool{true, false} {
+		arc := newTestArc(Vector3d{}, Vector3d{X: 2}, Vector3d{X: 1}, clockwise, ZAxis)
+		checkFloat(t, "angle", arc.angle(), math.Pi)
+	}
+}
+
+func TestArcFullCircle(t *testing.T) {
+	for _, clockwise := range []bool{true, false} {
+		arc := newTestArc(Vector3d{}, Vector3d{}, Vector3d{X: 1}, clockwise, ZAxis)
+		checkFloat(t, "angle", arc.angle(), 2*math.Pi)
+		checkFloat(t, "length", arc.getLength(), 2*math.Pi)
+	}
+}
+
+func TestArcHelix(t *testing.T) {
+	// The motion along the axis does not change the angle nor the directions
+	arc := newTestArc(Vector3d{}, Vector3d{X: 1, Y: 1, Z: 5}, Vector3d{X: 1}, true, ZAxis)
+
+	checkVector(t, "start direction", arc.getStartDirection(), Vector3d{Y: 1})
+	checkVector(t, "end direction", arc.getEndDirection(), Vector3d{X: -1})
+	checkFloat(t, "angle", arc.angle(), math.Pi/2)
+}
+
+func TestArcPlaneXZ(t *testing.T) {
+	// G18, clockwise when looking down the Y axis
+	clockwise := newTestArc(Vector3d{}, Vector3d{X: 1, Z: 1}, Vector3d{X: 1}, true, YAxis)
+	checkVector(t, "G2 start direction", clockwise.getStartDirection(), Vector3d{Z: -1})
+	checkFloat(t, "G2 angle", clockwise.angle(), 3*math.Pi/2)
+
+	counterClockwise := newTestArc(Vector3d{}, Vector3d{X: 1, Z: 1}, Vector3d{X: 1}, false, YAxis)
+	checkVector(t, "G3 start direction", counterClockwise.getStartDirection(), Vector3d{Z: 1})
+	checkVector(t, "G3 end direction", counterClockwise.getEndDirection(), Vector3d{X: -1})
+	checkFloat(t, "G3 angle", counterClockwise.angle(), math.Pi/2)
+}
+
+func TestArcPlaneYZ(t *testing.T) {
+	// G19, clockwise when looking down the X axis
+	clockwise := newTestArc(Vector3d{}, Vector3d{Y: 1, Z: 1}, Vector3d{Y: 1}, true, XAxis)
+	checkVector(t, "G2 start direction", clockwise.getStartDirection(), Vector3d{Z: 1})
+	checkVector(t, "G2 end direction", clockwise.getEndDirection(), Vector3d{Y: -1})
+	checkFloat(t, "G2 angle", clockwise.angle(), math.Pi/2)
+
+	counterClockwise := newTestArc(Vector3d{}, Vector3d{Y: 1, Z: 1}, Vector3d{Y: 1}, false, XAxis)
+	checkFloat(t, "G3 angle", counterClockwise.angle(), 3*math.Pi/2)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Names of the axes as used in the G-code, in the order of the Axis enum
+var axisNames = [...]string{"X", "Y", "Z", "A", "B", "C", "U", "V", "W", "E"}
+
+// Position of the machine axes indexed by Axis, the auxiliary axes in degrees
+// for the rotary axes. A position holds the axes up to the last one it uses,
+// the axes after it are at 0.
+type AxisPosition []float64
+
+// Create a position of the X, Y and Z axes
+func newAxisPosition(position Vector3d) AxisPosition {
+	return AxisPosition{position.X, position.Y, position.Z}
+}
+
+// Get the number of axes of positions holding the given numbers of axes
+func axisCount(counts ...int) int {
+	count := 0
+	for _, c := range counts {
+		if c > count {
+			count = c
+		}
+	}
+	return count
+}
+
+// Check if the axis is rotary
+func (a Axis) isRotary() bool {
+	return a == AAxis || a == BAxis || a == CAxis
+}
+
+// Get the name of the axis
+func (a Axis) String() string {
+	return axisNames[a]
+}
+
+// Get the position of an axis
+func (p AxisPosition) get(axis Axis) float64 {
+	if int(axis) < len(p) {
+		return p[axis]
+	}
+	return 0
+}
+
+// Get a copy of the position with an axis moved
+func (p AxisPosition) with(axis Axis, value float64) AxisPosition {
+	result := make(AxisPosition, axisCount(len(p), int(axis)+1))
+	copy(result, p)
+	result[axis] = value
+	return result
+}
+
+// Get the position of the X, Y and Z axes
+func (p AxisPosition) cartesian() Vector3d {
+	return Vector3d{X: p.get(XAxis), Y: p.get(YAxis), Z: p.get(ZAxis)}
+}
+
+// Get a copy of the position with the X, Y and Z axes moved
+func (p AxisPosition) withCartesian(position Vector3d) AxisPosition {
+	result := make(AxisPosition, axisCount(len(p), 3))
+	copy(result, p)
+	result[XAxis], result[YAxis], result[ZAxis] = position.X, position.Y, position.Z
+	return result
+}
+
+// Get a copy of the position with the auxiliary axes of another position
+func (p AxisPosition) withAuxiliary(auxiliary AxisPosition) AxisPosition {
+	return auxiliary.withCartesian(p.cartesian())
+}
+
+// Custom string representation of the position, with the auxiliary axes in use
+func (p AxisPosition) String() string {
+	var axes []string
+	for axis := range p {
+		if axis <= int(ZAxis) || p[axis] != 0 {
+			axes = append(axes, fmt.Sprintf("%s %7.3f", Axis(axis), p[axis]))
+		}
+	}
+	return "(" + strings.Join(axes, ", ") + ")"
+}
+
+// Check if two positions are the same
+func (p AxisPosition) equals(p2 AxisPosition) bool {
+	for axis := 0; axis < axisCount(len(p), len(p2)); axis++ {
+		if p.get(Axis(axis)) != p2.get(Axis(axis)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check if two positions have the same auxiliary axes
+func (p AxisPosition) sameAuxiliary(p2 AxisPosition) bool {
+	return p.withCartesian(Vector3d{}).equals(p2.withCartesian(Vector3d{}))
+}
+
+// subtract two positions
+func (p AxisPosition) subtract(p2 AxisPosition) AxisPosition {
+	result := make(AxisPosition, axisCount(len(p), len(p2)))
+	for axis := range result {
+		result[axis] = p.get(Axis(axis)) - p2.get(Axis(axis))
+	}
+	return result
+}
+
+// Length of the linear auxiliary axes (U, V, W)
+func (p AxisPosition) linearLength() float64 {
+	return math.Sqrt(p.get(UAxis)*p.get(UAxis) + p.get(VAxis)*p.get(VAxis) + p.get(WAxis)*p.get(WAxis))
+}
+
+// Length of the rotary axes (A, B, C), in degrees
+func (p AxisPosition) rotaryLength() float64 {
+	return math.Sqrt(p.get(AAxis)*p.get(AAxis) + p.get(BAxis)*p.get(BAxis) + p.get(CAxis)*p.get(CAxis))
+}
+
+// Length used for the feed rate of a movement without X, Y or Z motion. As in
+// RS274NGC, the linear axes are used if they move and the rotary axes
+// otherwise. Extruder only movements are measured along the filament.
+func (p AxisPosition) auxiliaryLength() float64 {
+	if length := p.linearLength(); length > 0 {
+		return length
+	}
+	if length := p.rotaryLength(); length > 0 {
+		return length
+	}
+	return math.Abs(p.get(EAxis))
+}
+
+// Check if only the extruder moves, the nozzle staying in place
+func (p AxisPosition) isExtruderOnly() bool {
+	return p.get(EAxis) != 0 && p.cartesian().length() == 0 && p.linearLength() == 0 && p.rotaryLength() == 0
+}
+
+// Limit a velocity or acceleration along a movement of the given length so
+// that no auxiliary axis of this displacement exceeds its maximum. The axes
+// without a maximum are limited to the fallback.
+func (p AxisPosition) limit(value float64, length float64, maximum AxisPosition, fallback float64) float64 {
+	for axis := AAxis; int(axis) < len(p); axis++ {
+		displacement := p[axis]
+		if displacement == 0 {
+			continue
+		}
+
+		axisMaximum := maximum.get(axis)
+		if axisMaximum <= 0 {
+			axisMaximum = fallback
+		}
+
+		axisLimit := axisMaximum * length / math.Abs(displacement)
+		if value > axisLimit {
+			value = axisLimit
+		}
+	}
+	return value
+}
+
+// Get the position at a fraction of the way to another position
+func (p AxisPosition) interpolate(end AxisPosition, ratio float64) AxisPosition {
+	result := make(AxisPosition, axisCount(len(p), len(end)))
+	for axis := range result {
+		start := p.get(Axis(axis))
+		result[axis] = start + (end.get(Axis(axis))-start)*ratio
+	}
+	return result
+}
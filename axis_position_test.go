@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAxisPositionHoldsTheAxesInUse(t *testing.T) {
+	position := newAxisPosition(Vector3d{X: 1, Y: 2, Z: 3})
+	if len(position) != 3 {
+		t.Fatalf("got %d axes, expected 3", len(position))
+	}
+
+	rotated := position.with(AAxis, 90)
+	if len(rotated) != 4 || rotated.get(AAxis) != 90 || position.get(AAxis) != 0 {
+		t.Fatalf("got %v from %v, expected A90 added to a copy", rotated, position)
+	}
+
+	// The axes after the last one are at 0
+	if !position.equals(rotated.with(AAxis, 0)) || position.equals(rotated) {
+		t.Errorf("%v and %v compare wrongly", position, rotated)
+	}
+	checkVector(t, "cartesian", rotated.cartesian(), Vector3d{X: 1, Y: 2, Z: 3})
+}
+
+// Plan a movement and get its velocity and acceleration limits
+func getMovementLimits(configuration *MachineConfiguration, lines []string) (float64, float64) {
+	planner := planLines(configuration, lines)
+	movements := planner.commandList.GetMovementList()
+	movement := movements[len(movements)-1]
+	planner.limitVelocity(movement)
+	return movement.getTargetVelocity(), planner.getMaxAcceleration(movement)
+}
+
+func TestAuxiliaryAxisLimits(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setAuxiliaryLimits(AAxis, 1000, 200)
+
+	// A rotary only movement is limited by its own axis, in degrees
+	velocity, acceleration := getMovementLimits(configuration, []string{"G1 A90 F60000"})
+	checkFloat(t, "rotary velocity", velocity, 200)
+	checkFloat(t, "rotary acceleration", acceleration, 1000)
+
+	// The rotary axis slows down the linear axes it moves with
+	velocity, _ = getMovementLimits(configuration, []string{"G1 X10 A90 F60000"})
+	checkFloat(t, "combined velocity", velocity, 200*10.0/90)
+}
+
+func TestAuxiliaryAxisWithoutLimits(t *testing.T) {
+	// The axes without limits use the lowest limits of X, Y and Z
+	configuration := defaultMachineConfiguration()
+	for _, line := range []string{"G1 A90 F60000", "G1 E5 F60000"} {
+		velocity, acceleration := getMovementLimits(configuration, []string{line})
+		checkFloat(t, line+" velocity", velocity, configuration.getMaxVelocity_scalar())
+		checkFloat(t, line+" acceleration", acceleration, configuration.getMaxAcceleraction_scalar())
+	}
+
+	// A movement of no axis stays finite
+	velocity, acceleration := getMovementLimits(configuration, []string{"G1 X0 F600"})
+	if math.IsNaN(velocity) || math.IsInf(acceleration, 0) || math.IsNaN(acceleration) {
+		t.Errorf("got a velocity of %g and an acceleration of %g for an empty movement", velocity, acceleration)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "fmt"
+
+// Take up the backlash of the axes reversing direction. The compensation is
+// executed at its own velocity on top of the following movement, starting at
+// a fraction of that movement, so its planned feed is not changed.
+type BacklashCompensation struct {
+	displacement Vector3d
+	velocity     float64
+	fraction     float64
+}
+
+// Create a new backlash compensation
+func newBacklashCompensation(displacement Vector3d, velocity float64, fraction float64) *BacklashCompensation {
+	return &BacklashCompensation{displacement: displacement, velocity: velocity, fraction: fraction}
+}
+
+// Get the displacement of the motors
+func (b *BacklashCompensation) getDisplacement() Vector3d {
+	return b.displacement
+}
+
+// Get the velocity of the compensation
+func (b *BacklashCompensation) getVelocity() float64 {
+	return b.velocity
+}
+
+// Get the fraction of the following movement at which the compensation starts
+func (b *BacklashCompensation) getFraction() float64 {
+	return b.fraction
+}
+
+// The compensation is executed while moving
+func (b *BacklashCompensation) requiresFullStop() bool {
+	return false
+}
+
+// Return a string representation of the backlash compensation
+func (b *BacklashCompensation) String() string {
+	return fmt.Sprintf("Backlash:    %7.3f at %3.0f%% of next move  Velocity: %7.3f m/s", b.displacement, b.fraction*100, b.velocity)
+}
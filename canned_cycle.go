@@ -0,0 +1,202 @@
+package main
+
+import "math"
+
+// Distance the tool backs off between pecks of a G73 chip breaking cycle
+const chipBreakRetract = 0.01
+
+// Distance above the previous peck depth the tool rapids down to in a G83 cycle
+const peckClearance = 0.01
+
+// Check if the command is a canned drilling cycle
+func isCannedCycle(command string) bool {
+	switch command {
+	case "G73", "G81", "G82", "G83", "G84", "G85", "G86", "G87", "G88", "G89":
+		return true
+	}
+	return false
+}
+
+// Rapid to a position, skipping moves that would not change the position
+func (m *MotionPlanner) cycleRapid(position Vector3d) {
+	if position == m.commandList.getPreviousPosition() {
+		return
+	}
+	m.commandList.addMovement(newRapidMovement(position, m.machine_configuration.getRapidVelocity()))
+}
+
+// Feed to a position, skipping moves that would not change the position
+func (m *MotionPlanner) cycleFeed(position Vector3d, feedrate float64) {
+	if position == m.commandList.getPreviousPosition() {
+		return
+	}
+	m.addFeedMovement(newLinearMovement(position, 0), feedrate)
+}
+
+// Dwell at the current position if a duration was given
+func (m *MotionPlanner) cycleDwell(duration float64) {
+	if duration <= 0 {
+		return
+	}
+	m.commandList.addCommand(newDwell(duration))
+}
+
+// Expand a canned cycle into rapid, feed and dwell commands. The R plane, Z
+// depth, Q peck increment and P dwell are modal through the parser, so lines
+// containing only X and Y drill a new hole with the same cycle.
+func (m *MotionPlanner) expandCannedCycle(gcodeLine GCodeCommand) {
+	params := gcodeLine.params
+	position := m.commandList.getPreviousPosition()
+
+	x, ok := params["X"]
+	if !ok {
+		x = position.X
+	}
+	y, ok := params["Y"]
+	if !ok {
+		y = position.Y
+	}
+	bottom, ok := params["Z"]
+	if !ok {
+		bottom = position.Z
+	}
+	retract, ok := params["R"]
+	if !ok {
+		retract = position.Z
+	}
+	feedrate := params["F"]
+	dwell := params["P"]
+
+	// G98 returns to the level the tool was at before the cycle, G99 to the R plane
+	clearance := retract
+	if m.retractToInitialLevel && position.Z > retract {
+		clearance = position.Z
+	}
+
+	// In absolute mode, L repeats the cycle on the same hole
+	repeats := 1
+	if l, ok := params["L"]; ok && l > 1 {
+		repeats = int(l)
+	}
+
+	// Cycles that stop or reverse the spindle restore it once out of the hole
+	spindle := m.spindle
+
+	// Preliminary motion: move up to the R plane before moving over the hole
+	if position.Z < retract {
+		m.cycleRapid(Vector3d{X: position.X, Y: position.Y, Z: retract})
+	}
+
+	for i := 0; i < repeats; i++ {
+		m.cycleRapid(Vector3d{X: x, Y: y, Z: m.commandList.getPreviousPosition().Z})
+		m.cycleRapid(Vector3d{X: x, Y: y, Z: retract})
+
+		hole := Vector3d{X: x, Y: y, Z: bottom}
+		retractPlane := Vector3d{X: x, Y: y, Z: retract}
+
+		switch gcodeLine.command {
+		case "G81":
+			// Drilling
+			m.cycleFeed(hole, feedrate)
+		case "G82":
+			// Drilling with dwell
+			m.cycleFeed(hole, feedrate)
+			m.cycleDwell(dwell)
+		case "G73", "G83":
+			// Peck drilling, G73 breaks the chip and G83 clears it out of the hole
+			m.peck(gcodeLine.command == "G73", hole, retract, params["Q"], feedrate)
+		case "G84":
+			// Tapping, reverse the spindle at the bottom of the hole
+			m.cycleFeed(hole, feedrate)
+			m.cycleDwell(dwell)
+			m.setSpindle(reverseSpindle(spindle.direction), spindle.speed)
+			m.cycleFeed(retractPlane, feedrate)
+			m.setSpindle(spindle.direction, spindle.speed)
+		case "G85":
+			// Boring, feed out
+			m.cycleFeed(hole, feedrate)
+			m.cycleFeed(retractPlane, feedrate)
+		case "G86":
+			// Boring, spindle stop, rapid out
+			m.cycleFeed(hole, feedrate)
+			m.cycleDwell(dwell)
+			m.setSpindle(SpindleOff, spindle.speed)
+		case "G87":
+			// Back boring
+			m.backBore(hole, retract, params, feedrate, spindle)
+		case "G88":
+			// Boring, spindle stop, manual out
+			m.cycleFeed(hole, feedrate)
+			m.cycleDwell(dwell)
+			m.setSpindle(SpindleOff, spindle.speed)
+			m.commandList.addCommand(newProgramStop(ProgramPause))
+		case "G89":
+			// Boring, dwell, feed out
+			m.cycleFeed(hole, feedrate)
+			m.cycleDwell(dwell)
+			m.cycleFeed(retractPlane, feedrate)
+		}
+
+		m.cycleRapid(Vector3d{X: x, Y: y, Z: clearance})
+
+		if m.spindle != spindle {
+			m.setSpindle(spindle.direction, spindle.speed)
+		}
+	}
+}
+
+// Get the opposite spindle direction
+func reverseSpindle(direction SpindleDirection) SpindleDirection {
+	switch direction {
+	case SpindleClockwise:
+		return SpindleCounterClockwise
+	case SpindleCounterClockwise:
+		return SpindleClockwise
+	}
+	return SpindleOff
+}
+
+// Drill a hole in increments of peckDepth
+func (m *MotionPlanner) peck(chipBreak bool, hole Vector3d, retract float64, peckDepth float64, feedrate float64) {
+	if peckDepth <= 0 {
+		peckDepth = retract - hole.Z
+	}
+
+	depth := retract
+	for depth > hole.Z {
+		depth = math.Max(depth-peckDepth, hole.Z)
+		m.cycleFeed(Vector3d{X: hole.X, Y: hole.Y, Z: depth}, feedrate)
+
+		if depth <= hole.Z {
+			break
+		}
+
+		if chipBreak {
+			// Back off slightly to break the chip
+			m.cycleRapid(Vector3d{X: hole.X, Y: hole.Y, Z: depth + chipBreakRetract})
+		} else {
+			// Retract out of the hole to clear the chips and rapid back down
+			m.cycleRapid(Vector3d{X: hole.X, Y: hole.Y, Z: retract})
+			m.cycleRapid(Vector3d{X: hole.X, Y: hole.Y, Z: math.Min(depth+peckClearance, retract)})
+		}
+	}
+}
+
+// Back bore from the bottom of the part up to the K level. The tool enters
+// the hole offset by I and J so the boring bar clears the bore.
+func (m *MotionPlanner) backBore(hole Vector3d, retract float64, params map[string]float64, feedrate float64, spindle SpindleCommand) {
+	offset := Vector3d{X: hole.X + params["I"], Y: hole.Y + params["J"], Z: retract}
+	top := Vector3d{X: hole.X, Y: hole.Y, Z: params["K"]}
+
+	// The spindle is stopped while the bar passes through the bore
+	m.setSpindle(SpindleOff, spindle.speed)
+	m.cycleRapid(offset)
+	m.cycleRapid(Vector3d{X: offset.X, Y: offset.Y, Z: hole.Z})
+	m.cycleRapid(hole)
+	m.setSpindle(spindle.direction, spindle.speed)
+	m.cycleFeed(top, feedrate)
+	m.cycleFeed(hole, feedrate)
+	m.setSpindle(SpindleOff, spindle.speed)
+	m.cycleRapid(Vector3d{X: offset.X, Y: offset.Y, Z: hole.Z})
+	m.cycleRapid(Vector3d{X: offset.X, Y: offset.Y, Z: retract})
+}
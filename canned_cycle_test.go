@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestCannedCycleReturnToInitialLevel(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G0 X0 Y0 Z10", "G98 G81 X5 Y5 Z-2 R2 F120", "X10"})
+
+	checkEndPositions(t, planner, []Vector3d{
+		{Z: 10},
+		{X: 5, Y: 5, Z: 10}, {X: 5, Y: 5, Z: 2}, {X: 5, Y: 5, Z: -2}, {X: 5, Y: 5, Z: 10},
+		{X: 10, Y: 5, Z: 10}, {X: 10, Y: 5, Z: 2}, {X: 10, Y: 5, Z: -2}, {X: 10, Y: 5, Z: 10},
+	})
+}
+
+func TestCannedCycleReturnToRPlane(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G0 X0 Y0 Z10", "G99 G81 X5 Y5 Z-2 R2 F120", "X10"})
+
+	checkEndPositions(t, planner, []Vector3d{
+		{Z: 10},
+		{X: 5, Y: 5, Z: 10}, {X: 5, Y: 5, Z: 2}, {X: 5, Y: 5, Z: -2}, {X: 5, Y: 5, Z: 2},
+		{X: 10, Y: 5, Z: 2}, {X: 10, Y: 5, Z: -2}, {X: 10, Y: 5, Z: 2},
+	})
+}
+
+func TestCannedCyclePeckDrilling(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G0 X0 Y0 Z5", "G83 Z-3 R1 Q1.5 F100"})
+
+	checkEndPositions(t, planner, []Vector3d{
+		{Z: 5}, {Z: 1},
+		{Z: -0.5}, {Z: 1}, {Z: -0.49},
+		{Z: -2}, {Z: 1}, {Z: -1.99},
+		{Z: -3}, {Z: 5},
+	})
+}
+
+func TestCannedCycleChipBreaking(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G0 X0 Y0 Z5", "G73 Z-2 R1 Q1.5 F100"})
+
+	checkEndPositions(t, planner, []Vector3d{{Z: 5}, {Z: 1}, {Z: -0.5}, {Z: -0.49}, {Z: -2}, {Z: 5}})
+}
+
+func TestCannedCycleRepeats(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G0 X0 Y0 Z5", "G81 X1 Z-1 R1 L3 F100"})
+
+	holes := 0
+	for _, position := range getEndPositions(planner) {
+		if position.Z == -1 {
+			holes++
+		}
+	}
+	if holes != 3 {
+		t.Fatalf("drilled %d times, expected 3", holes)
+	}
+}
+
+func TestCannedCycleDwell(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G0 X0 Y0 Z5", "G82 Z-1 R1 P0.5 F100"})
+
+	dwells := getCommands[*Dwell](planner)
+	if len(dwells) != 1 || dwells[0].getDuration() != 0.5 {
+		t.Fatalf("got dwells %v, expected one of 0.5 s", dwells)
+	}
+}
+
+func TestCannedCycleTappingReversesSpindle(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"M3 S500", "G0 X0 Y0 Z5", "G84 Z-2 R1 F100"})
+
+	var directions []SpindleDirection
+	for _, spindle := range getCommands[*SpindleCommand](planner) {
+		directions = append(directions, spindle.getDirection())
+	}
+	expected := []SpindleDirection{SpindleClockwise, SpindleCounterClockwise, SpindleClockwise}
+	if len(directions) != len(expected) {
+		t.Fatalf("got spindle directions %v, expected %v", directions, expected)
+	}
+	for i := range expected {
+		if directions[i] != expected[i] {
+			t.Fatalf("got spindle directions %v, expected %v", directions, expected)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package main
+
+// Create an interface for the commands executed in order with the movements
+type Command interface {
+	// Check if the machine must be stopped before the command is executed
+	requiresFullStop() bool
+	String() string
+}
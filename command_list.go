@@ -11,21 +11,39 @@ import "fmt"
 */
 type CommandList struct {
 	arr               []interface{}
-	previous_position Vector3d
+	previous_position AxisPosition
 }
 
 // Add a Movement
 func (c *CommandList) addMovement(movement Movement) {
-	movement.setStartPosition(c.previous_position)
-	c.previous_position = movement.getEndPosition()
+	movement.setStartAxisPosition(c.previous_position)
+	c.previous_position = movement.getEndAxisPosition()
 
 	c.arr = append(c.arr, movement)
 
 }
 
+// Add a motion synchronized with the spindle
+func (c *CommandList) addSynchronizedMotion(motion *SynchronizedMotion) {
+	motion.setStartPosition(c.getPreviousPosition())
+	c.previous_position = c.previous_position.withCartesian(motion.getEndPosition())
+
+	c.arr = append(c.arr, motion)
+}
+
+// Add a command that does not move the machine
+func (c *CommandList) addCommand(command Command) {
+	c.arr = append(c.arr, command)
+}
+
+// Get the position of the X, Y and Z axes at the end of the commands
+func (c *CommandList) getPreviousPosition() Vector3d {
+	return c.previous_position.cartesian()
+}
+
 // New Command List
 func NewCommandList() *CommandList {
-	return &CommandList{arr: []interface{}{}, previous_position: Vector3d{X: 0, Y: 0, Z: 0}}
+	return &CommandList{arr: []interface{}{}, previous_position: newAxisPosition(Vector3d{X: 0, Y: 0, Z: 0})}
 }
 
 // Return Movement list
@@ -41,12 +59,121 @@ func (c *CommandList) GetMovementList() []Movement {
 	return movement_list
 }
 
+// Walk the command list in order and return, for each movement, the state
+// left by the commands before it, and the state after the last command.
+// update gives the state after a command.
+func getStateList[T any](c *CommandList, state T, update func(T, Command) T) ([]T, T) {
+	var state_list []T
+	for _, command := range c.arr {
+		switch command.(type) {
+		case Movement:
+			state_list = append(state_list, state)
+		case Command:
+			state = update(state, command.(Command))
+		}
+	}
+
+	return state_list, state
+}
+
+// Return, for each movement, if the machine must be stopped at its end
+func (c *CommandList) GetFullStopList() []bool {
+	// Count the stops, a movement is followed by one if the count changes
+	// before the next movement
+	stop_counts, total := getStateList(c, 0, func(count int, command Command) int {
+		if command.requiresFullStop() {
+			count++
+		}
+		return count
+	})
+
+	full_stop_list := make([]bool, len(stop_counts))
+	for i := range stop_counts {
+		next := total
+		if i+1 < len(stop_counts) {
+			next = stop_counts[i+1]
+		}
+		full_stop_list[i] = next > stop_counts[i]
+	}
+
+	return full_stop_list
+}
+
+// Return, for each movement, the path control mode active at its end. The
+// movements before the first path control command use the given one.
+func (c *CommandList) GetPathControlList(pathControl *PathControl) []*PathControl {
+	path_control_list, _ := getStateList(c, pathControl, func(pathControl *PathControl, command Command) *PathControl {
+		if next, ok := command.(*PathControl); ok {
+			return next
+		}
+		return pathControl
+	})
+
+	return path_control_list
+}
+
+// Return, for each movement, the tool center point control it runs with
+func (c *CommandList) GetToolCenterPointList() []*ToolCenterPoint {
+	tool_center_point_list, _ := getStateList(c, newToolCenterPoint(false, 0), func(toolCenterPoint *ToolCenterPoint, command Command) *ToolCenterPoint {
+		if next, ok := command.(*ToolCenterPoint); ok {
+			return next
+		}
+		return toolCenterPoint
+	})
+
+	return tool_center_point_list
+}
+
+// Return, for each movement, the spindle state it runs with
+func (c *CommandList) GetSpindleList() []SpindleCommand {
+	spindle_list, _ := getStateList(c, SpindleCommand{direction: SpindleOff}, func(spindle SpindleCommand, command Command) SpindleCommand {
+		if next, ok := command.(*SpindleCommand); ok {
+			return *next
+		}
+		return spindle
+	})
+
+	return spindle_list
+}
+
+// Return, for each movement, if the plasma torch is on
+func (c *CommandList) GetTorchList() []bool {
+	torch_list, _ := getStateList(c, false, func(on bool, command Command) bool {
+		if torch, ok := command.(*TorchCommand); ok {
+			return torch.isOn()
+		}
+		return on
+	})
+
+	return torch_list
+}
+
+// Return, for each movement, the operator messages to deliver before it
+// starts. The last entry holds the messages after the last movement.
+func (c *CommandList) GetMessageList() [][]*OperatorMessage {
+	var message_list [][]*OperatorMessage
+	var messages []*OperatorMessage
+	for _, command := range c.arr {
+		switch command.(type) {
+		case Movement:
+			message_list = append(message_list, messages)
+			messages = nil
+		case *OperatorMessage:
+			messages = append(messages, command.(*OperatorMessage))
+		}
+	}
+
+	return append(message_list, messages)
+}
+
 // Print the command list
 func (c *CommandList) print() {
 	for _, command := range c.arr {
 		switch command.(type) {
 		case Movement:
 			fmt.Println(command.(Movement))
+		case Command:
+			fmt.Println(command.(Command))
 		}
 	}
 
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandListFullStops(t *testing.T) {
+	// The stop before the first movement has no movement to stop
+	planner := planLines(defaultMachineConfiguration(), []string{"M0", "G1 X1 F100", "X2", "G4 P1", "M7", "X3", "X4", "M30"})
+
+	expected := []bool{false, true, false, true}
+	if full_stops := planner.commandList.GetFullStopList(); !reflect.DeepEqual(full_stops, expected) {
+		t.Fatalf("got full stops %v, expected %v", full_stops, expected)
+	}
+}
+
+func TestCommandListStates(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G1 X1 F100", "M3 S1000", "G61.1", "X2", "M5", "G64 P0.1", "X3"})
+
+	var directions []SpindleDirection
+	for _, spindle := range planner.commandList.GetSpindleList() {
+		directions = append(directions, spindle.getDirection())
+	}
+	if expected := []SpindleDirection{SpindleOff, SpindleClockwise, SpindleOff}; !reflect.DeepEqual(directions, expected) {
+		t.Fatalf("got spindle directions %v, expected %v", directions, expected)
+	}
+
+	defaultPathControl := newPathControl(PathBlending, 0.1, 0)
+	var modes []PathControlMode
+	for _, pathControl := range planner.commandList.GetPathControlList(defaultPathControl) {
+		modes = append(modes, pathControl.getMode())
+	}
+	if expected := []PathControlMode{PathBlending, PathExactStop, PathBlending}; !reflect.DeepEqual(modes, expected) {
+		t.Fatalf("got path control modes %v, expected %v", modes, expected)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import "math"
+
+// Insert the backlash and pitch error compensation before the movements once
+// they are planned. The machine applies them on top of the planned movements.
+func (m *MotionPlanner) compensate() {
+	config := m.machine_configuration
+	zero := Vector3d{X: 0, Y: 0, Z: 0}
+	if config.backlash == zero && config.pitchErrorTables == [3]*PitchErrorTable{} {
+		return
+	}
+
+	// Last direction of each axis, 0 until the axis moves
+	directions := [3]float64{}
+	correction := zero
+
+	var arr []interface{}
+	for _, command := range m.commandList.arr {
+		// The backlash and the pitch error of the threads are corrected as well
+		if motion, ok := command.(*SynchronizedMotion); ok {
+			start := motion.getStartPosition()
+			displacement := motion.getEndPosition().subtract(start)
+			positionAt := func(fraction float64) Vector3d { return start.Add(displacement.scale(fraction)) }
+			if config.backlash != zero {
+				for _, compensation := range m.getBacklashCompensation(positionAt, &directions) {
+					arr = append(arr, compensation)
+				}
+			}
+			for _, compensation := range m.getPitchErrorCompensation(positionAt, config.getPitchCrossings(start, motion.getEndPosition()), &correction) {
+				arr = append(arr, compensation)
+			}
+		}
+
+		movement, ok := command.(Movement)
+		if !ok {
+			arr = append(arr, command)
+			continue
+		}
+
+		if config.backlash != zero {
+			for _, compensation := range m.getBacklashCompensation(movement.getPositionAt, &directions) {
+				arr = append(arr, compensation)
+			}
+		}
+
+		// The correction is linear between the positions of the tables, the
+		// arcs are sampled
+		var fractions []float64
+		if _, ok := movement.(*LinearMovement); ok {
+			fractions = config.getPitchCrossings(movement.getStartPosition(), movement.getEndPosition())
+		} else {
+			for i := 1; i <= motorRatioSegments; i++ {
+				fractions = append(fractions, float64(i)/motorRatioSegments)
+			}
+		}
+		for _, compensation := range m.getPitchErrorCompensation(movement.getPositionAt, fractions, &correction) {
+			arr = append(arr, compensation)
+		}
+
+		arr = append(arr, movement)
+	}
+
+	m.commandList.arr = arr
+}
+
+// Get the pitch error compensation at the fractions of the following motion,
+// none when the correction does not change along it
+func (m *MotionPlanner) getPitchErrorCompensation(positionAt func(float64) Vector3d, fractions []float64, correction *Vector3d) []*PitchErrorCompensation {
+	var compensations []*PitchErrorCompensation
+	changed := false
+	for _, fraction := range fractions {
+		value := m.machine_configuration.getPitchCorrection(positionAt(fraction))
+		changed = changed || value != *correction
+		compensations = append(compensations, newPitchErrorCompensation(value, fraction))
+	}
+
+	if !changed {
+		return nil
+	}
+	*correction = compensations[len(compensations)-1].getCorrection()
+	return compensations
+}
+
+// Get the backlash compensation for the axes reversing direction along the
+// following motion. Arcs can reverse axes in their middle, so the motion is
+// sampled.
+func (m *MotionPlanner) getBacklashCompensation(positionAt func(float64) Vector3d, directions *[3]float64) []*BacklashCompensation {
+	config := m.machine_configuration
+	backlash := [3]float64{config.backlash.X, config.backlash.Y, config.backlash.Z}
+
+	var compensations []*BacklashCompensation
+
+	previous := positionAt(0)
+	for i := 1; i <= motorRatioSegments; i++ {
+		current := positionAt(float64(i) / motorRatioSegments)
+		displacement := current.subtract(previous)
+		deltas := [3]float64{displacement.X, displacement.Y, displacement.Z}
+
+		compensation := [3]float64{}
+		reversed := false
+		for axis, delta := range deltas {
+			if math.Abs(delta) < tangentAngleTolerance {
+				continue
+			}
+
+			direction := math.Copysign(1, delta)
+			if directions[axis] != 0 && direction != directions[axis] && backlash[axis] != 0 {
+				compensation[axis] = direction * backlash[axis]
+				reversed = true
+			}
+			directions[axis] = direction
+		}
+
+		if reversed {
+			displacement := Vector3d{X: compensation[0], Y: compensation[1], Z: compensation[2]}
+			compensations = append(compensations, newBacklashCompensation(displacement, config.backlashVelocity, float64(i-1)/motorRatioSegments))
+		}
+
+		previous = current
+	}
+
+	return compensations
+}
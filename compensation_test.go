@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Create a machine with an X pitch error rising to 0.1 at X10 and back to 0 at X20
+func newPitchErrorConfiguration() *MachineConfiguration {
+	configuration := defaultMachineConfiguration()
+	table, _ := newPitchErrorTable([]float64{0, 10, 20}, []float64{0, 0.1, 0})
+	configuration.setPitchErrorTable(XAxis, table)
+	return configuration
+}
+
+// Check the fraction and X correction of the pitch error compensations
+func checkPitchErrorCompensations(t *testing.T, compensations []*PitchErrorCompensation, expected [][2]float64) {
+	t.Helper()
+	if len(compensations) != len(expected) {
+		t.Fatalf("got compensations %v, expected %v", compensations, expected)
+	}
+	for i := range expected {
+		checkFloat(t, "fraction", compensations[i].getFraction(), expected[i][0])
+		checkFloat(t, "correction", compensations[i].getCorrection().X, expected[i][1])
+	}
+}
+
+func TestPitchErrorAlongMovements(t *testing.T) {
+	planner := planLines(newPitchErrorConfiguration(), []string{"G1 X20 F600", "Y5", "X15"})
+	planner.compensate()
+
+	// The correction is given at the table positions crossed by the movements
+	// and does not change while X stands still
+	checkPitchErrorCompensations(t, getCommands[*PitchErrorCompensation](planner), [][2]float64{{0.5, 0.1}, {1, 0}, {1, 0.05}})
+}
+
+func TestPitchErrorAlongSynchronizedMotion(t *testing.T) {
+	planner := planLines(newPitchErrorConfiguration(), []string{"M3 S500", "G33 X10 K1"})
+	planner.compensate()
+
+	checkPitchErrorCompensations(t, getCommands[*PitchErrorCompensation](planner), [][2]float64{{1, 0.1}})
+	var order []string
+	for _, command := range planner.commandList.arr {
+		switch command.(type) {
+		case *PitchErrorCompensation:
+			order = append(order, "compensation")
+		case *SynchronizedMotion:
+			order = append(order, "motion")
+		}
+	}
+	if len(order) != 2 || order[0] != "compensation" {
+		t.Errorf("got %v, expected the compensation before the synchronized motion", order)
+	}
+}
+
+func TestBacklashOnReversal(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setBacklash(Vector3d{X: 0.05}, 20)
+	planner := planLines(configuration, []string{"G1 X10 F600", "X0", "Y5", "X-5", "X5"})
+	planner.compensate()
+
+	// Only the reversals of X are compensated, at the start of the movements
+	compensations := getCommands[*BacklashCompensation](planner)
+	if len(compensations) != 2 {
+		t.Fatalf("got compensations %v, expected 2", compensations)
+	}
+	checkVector(t, "first reversal", compensations[0].getDisplacement(), Vector3d{X: -0.05})
+	checkVector(t, "second reversal", compensations[1].getDisplacement(), Vector3d{X: 0.05})
+	for _, compensation := range compensations {
+		checkFloat(t, "fraction", compensation.getFraction(), 0)
+		checkFloat(t, "velocity", compensation.getVelocity(), 20)
+	}
+}
+
+func TestBacklashAroundThreads(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setBacklash(Vector3d{Z: 0.02}, 20)
+	planner := planLines(configuration, []string{"M3 S500", "G0 X5 Z-10", "G33 Z5 K1.5", "G0 Z-10"})
+	planner.compensate()
+
+	// The thread cut upwards after the plunge reverses Z, and the plunge
+	// after the thread reverses it back
+	var order []string
+	var displacements []Vector3d
+	for _, command := range planner.commandList.arr {
+		switch command := command.(type) {
+		case *BacklashCompensation:
+			order = append(order, "backlash")
+			displacements = append(displacements, command.getDisplacement())
+		case *SynchronizedMotion:
+			order = append(order, "thread")
+		case Movement:
+			order = append(order, "move")
+		}
+	}
+	expected := []string{"move", "backlash", "thread", "backlash", "move"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Fatalf("got %v, expected %v", order, expected)
+	}
+	checkVector(t, "reversal into the thread", displacements[0], Vector3d{Z: 0.02})
+	checkVector(t, "reversal out of the thread", displacements[1], Vector3d{Z: -0.02})
+}
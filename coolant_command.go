@@ -0,0 +1,34 @@
+package main
+
+import "fmt"
+
+// Set the coolant state (M7, M8, M9)
+type CoolantCommand struct {
+	mist  bool
+	flood bool
+}
+
+// Create a new coolant command
+func newCoolantCommand(mist bool, flood bool) *CoolantCommand {
+	return &CoolantCommand{mist: mist, flood: flood}
+}
+
+// Get the mist state
+func (c *CoolantCommand) getMist() bool {
+	return c.mist
+}
+
+// Get the flood state
+func (c *CoolantCommand) getFlood() bool {
+	return c.flood
+}
+
+// Coolant can be switched while moving
+func (c *CoolantCommand) requiresFullStop() bool {
+	return false
+}
+
+// Return a string representation of the coolant command
+func (c *CoolantCommand) String() string {
+	return fmt.Sprintf("Coolant:     Mist: %t  Flood: %t", c.mist, c.flood)
+}
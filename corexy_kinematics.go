@@ -0,0 +1,43 @@
+package main
+
+// CoreXY kinematics, both X/Y motors move for a move along X or Y. An H-bot
+// uses the same equations, only the belt is routed as a single H instead of two
+// crossed loops, which can reverse the direction of Y.
+type CoreXYKinematics struct {
+	// Direction of Y for the motors, 1 or -1
+	ySign                float64
+	maxMotorVelocity     Vector3d
+	maxMotorAcceleration Vector3d
+}
+
+// Create a new CoreXY kinematics with the limits of the A, B and Z motors
+func newCoreXYKinematics(maxMotorVelocity Vector3d, maxMotorAcceleration Vector3d) *CoreXYKinematics {
+	return &CoreXYKinematics{ySign: 1, maxMotorVelocity: maxMotorVelocity, maxMotorAcceleration: maxMotorAcceleration}
+}
+
+// Create a new H-bot kinematics with the direction of Y for the motors and the
+// limits of the A, B and Z motors
+func newHBotKinematics(ySign float64, maxMotorVelocity Vector3d, maxMotorAcceleration Vector3d) *CoreXYKinematics {
+	return &CoreXYKinematics{ySign: ySign, maxMotorVelocity: maxMotorVelocity, maxMotorAcceleration: maxMotorAcceleration}
+}
+
+// Get the motor positions for a cartesian position
+func (k *CoreXYKinematics) inverse(position Vector3d) Vector3d {
+	y := k.ySign * position.Y
+	return Vector3d{X: position.X + y, Y: position.X - y, Z: position.Z}
+}
+
+// Get the cartesian position for the motor positions
+func (k *CoreXYKinematics) forward(motors Vector3d) Vector3d {
+	return Vector3d{X: (motors.X + motors.Y) / 2, Y: k.ySign * (motors.X - motors.Y) / 2, Z: motors.Z}
+}
+
+// Get the maximum velocity of each motor
+func (k *CoreXYKinematics) getMaxMotorVelocity() Vector3d {
+	return k.maxMotorVelocity
+}
+
+// Get the maximum acceleration of each motor
+func (k *CoreXYKinematics) getMaxMotorAcceleration() Vector3d {
+	return k.maxMotorAcceleration
+}
@@ -0,0 +1,111 @@
+package main
+
+import "math"
+
+// Replace the sharp corners between linear movements by tangent arcs that stay
+// within the blending tolerance, so the tool rounds the corner at constant
+// speed instead of slowing down into it.
+func (m *MotionPlanner) blendCorners() {
+	movements := m.commandList.GetMovementList()
+	full_stops := m.commandList.GetFullStopList()
+	path_controls := m.getPathControlList()
+
+	// Arcs to insert after each movement
+	blends := make(map[Movement]*ArcMovement)
+
+	for i := 0; i < len(movements)-1; i++ {
+		if full_stops[i] || path_controls[i].mode != PathBlending {
+			continue
+		}
+
+		arc := m.createBlendArc(movements[i], movements[i+1], path_controls[i].tolerance)
+		if arc != nil {
+			blends[movements[i]] = arc
+		}
+	}
+
+	var arr []interface{}
+	for _, command := range m.commandList.arr {
+		arr = append(arr, command)
+
+		if movement, ok := command.(Movement); ok {
+			if arc, ok := blends[movement]; ok {
+				arr = append(arr, arc)
+			}
+		}
+	}
+
+	m.commandList.arr = arr
+}
+
+// Create the arc joining two linear movements and shorten the movements so
+// they end and start on the arc. Return nil if the corner cannot be blended.
+func (m *MotionPlanner) createBlendArc(movement1 Movement, movement2 Movement, tolerance float64) *ArcMovement {
+	line1, ok1 := movement1.(*LinearMovement)
+	line2, ok2 := movement2.(*LinearMovement)
+	if !ok1 || !ok2 || tolerance <= 0 || line1.getLength() == 0 || line2.getLength() == 0 {
+		return nil
+	}
+
+	// The auxiliary axes could not follow the corner
+	if !line1.getStartAxisPosition().sameAuxiliary(line1.getEndAxisPosition()) || !line2.getStartAxisPosition().sameAuxiliary(line2.getEndAxisPosition()) {
+		return nil
+	}
+
+	direction1 := line1.getStartDirection()
+	direction2 := line2.getStartDirection()
+
+	// Angle the path turns by at the corner
+	turn := direction1.AngleWith(direction2)
+	if turn < tangentAngleTolerance || math.Pi-turn < tangentAngleTolerance {
+		return nil
+	}
+
+	// Arcs can only be made in the XY, XZ and YZ planes
+	axis, ok := principalAxis(direction1.Cross(direction2))
+	if !ok {
+		return nil
+	}
+
+	// Radius for which the middle of the arc is at the tolerance from the corner
+	radius := tolerance * math.Cos(turn/2) / (1 - math.Cos(turn/2))
+	distance := radius * math.Tan(turn/2)
+
+	// Do not use more than half of a movement, the other half is for the next corner
+	maxDistance := math.Min(line1.getLength(), line2.getLength()) / 2
+	if distance > maxDistance {
+		distance = maxDistance
+		radius = distance / math.Tan(turn/2)
+	}
+
+	corner := line1.getEndPosition()
+	start := corner.subtract(direction1.scale(distance))
+	end := corner.Add(direction2.scale(distance))
+	center := corner.Add(direction2.subtract(direction1).normalize().scale(radius / math.Cos(turn/2)))
+
+	// Go around the corner at the highest speed the acceleration allows
+	acceleration := m.machine_configuration.getMaxAcceleractionForTwoVectors(direction1, direction2)
+	velocity := math.Min(math.Min(line1.getTargetVelocity(), line2.getTargetVelocity()), math.Sqrt(acceleration*radius))
+
+	arc := newArcMovement(end, center.subtract(start), velocity, false, axis)
+	arc.setStartPosition(start)
+	arc.setStartAxisPosition(line1.getEndAxisPosition().withCartesian(start))
+	if arc.getStartDirection().Dot(direction1) < 0 {
+		arc.clockwise = true
+	}
+
+	line1.setEndPosition(start)
+	line2.setStartPosition(end)
+
+	return arc
+}
+
+// Get the axis a vector is parallel to
+func principalAxis(v Vector3d) (Axis, bool) {
+	for _, axis := range []Axis{XAxis, YAxis, ZAxis} {
+		if v.project(axis).length() <= tangentAngleTolerance*v.length() {
+			return axis, true
+		}
+	}
+	return ZAxis, false
+}
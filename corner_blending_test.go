@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Blend the corner of a program with two movements and return the arc
+func blendCorner(t *testing.T, lines []string) (Movement, *ArcMovement, Movement) {
+	t.Helper()
+	configuration := defaultMachineConfiguration()
+	configuration.setCornerBlending(true)
+	planner := planLines(configuration, lines)
+	planner.blendCorners()
+
+	movements := planner.commandList.GetMovementList()
+	if len(movements) != 3 {
+		t.Fatalf("got %d movements, expected the two lines and the arc", len(movements))
+	}
+	arc, ok := movements[1].(*ArcMovement)
+	if !ok {
+		t.Fatalf("got %v between the lines, expected an arc", movements[1])
+	}
+	return movements[0], arc, movements[2]
+}
+
+func TestCornerBlendingTangentArc(t *testing.T) {
+	for _, turn := range []struct {
+		corner    string
+		clockwise bool
+	}{{"Y10", false}, {"Y-10", true}} {
+		line1, arc, line2 := blendCorner(t, []string{"G64 P0.1", "G1 X10 F600", turn.corner})
+
+		if arc.clockwise != turn.clockwise {
+			t.Errorf("%s: got a clockwise arc %t, expected %t", turn.corner, arc.clockwise, turn.clockwise)
+		}
+
+		// The arc joins the lines without a corner
+		checkVector(t, "arc start", arc.getStartPosition(), line1.getEndPosition())
+		checkVector(t, "arc end", arc.getEndPosition(), line2.getStartPosition())
+		checkFloat(t, "angle into the arc", line1.getEndDirection().AngleWith(arc.getStartDirection()), math.Pi)
+		checkFloat(t, "angle out of the arc", arc.getEndDirection().AngleWith(line2.getStartDirection()), math.Pi)
+
+		// The middle of the arc is at the tolerance from the programmed corner
+		checkFloat(t, "deviation", arc.getPositionAt(0.5).subtract(Vector3d{X: 10}).length(), 0.1)
+	}
+}
+
+func TestCornerBlendingExactStop(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setCornerBlending(true)
+	planner := planLines(configuration, []string{"G61", "G1 X10 F600", "Y10"})
+	planner.blendCorners()
+
+	if movements := planner.commandList.GetMovementList(); len(movements) != 2 {
+		t.Fatalf("got %d movements, expected the corner to be kept", len(movements))
+	}
+}
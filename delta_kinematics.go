@@ -0,0 +1,74 @@
+package main
+
+import "math"
+
+// Linear delta kinematics, three carriages move vertically on towers placed
+// every 120 degrees and hold the effector with rods of equal length
+type DeltaKinematics struct {
+	radius               float64
+	rodLength            float64
+	towers               [3]Vector3d
+	maxMotorVelocity     Vector3d
+	maxMotorAcceleration Vector3d
+}
+
+// Create a new delta kinematics. The radius is the horizontal distance between
+// the rod joints of a carriage and of the effector when it is centered.
+func newDeltaKinematics(radius float64, rodLength float64, maxMotorVelocity Vector3d, maxMotorAcceleration Vector3d) *DeltaKinematics {
+	k := &DeltaKinematics{radius: radius, rodLength: rodLength, maxMotorVelocity: maxMotorVelocity, maxMotorAcceleration: maxMotorAcceleration}
+
+	for i, angle := range []float64{210, 330, 90} {
+		k.towers[i] = Vector3d{X: radius * math.Cos(angle*math.Pi/180), Y: radius * math.Sin(angle*math.Pi/180), Z: 0}
+	}
+
+	return k
+}
+
+// Get the carriage heights for a cartesian position. Positions out of reach
+// of the rods give NaN.
+func (k *DeltaKinematics) inverse(position Vector3d) Vector3d {
+	var heights [3]float64
+	for i, tower := range k.towers {
+		dx := position.X - tower.X
+		dy := position.Y - tower.Y
+		heights[i] = position.Z + math.Sqrt(k.rodLength*k.rodLength-dx*dx-dy*dy)
+	}
+	return Vector3d{X: heights[0], Y: heights[1], Z: heights[2]}
+}
+
+// Get the cartesian position for the carriage heights by intersecting the
+// three spheres of the rods
+func (k *DeltaKinematics) forward(motors Vector3d) Vector3d {
+	p1 := Vector3d{X: k.towers[0].X, Y: k.towers[0].Y, Z: motors.X}
+	p2 := Vector3d{X: k.towers[1].X, Y: k.towers[1].Y, Z: motors.Y}
+	p3 := Vector3d{X: k.towers[2].X, Y: k.towers[2].Y, Z: motors.Z}
+
+	d := p2.subtract(p1).length()
+	ex := p2.subtract(p1).normalize()
+	i := ex.Dot(p3.subtract(p1))
+	ey := p3.subtract(p1).subtract(ex.scale(i)).normalize()
+	ez := ex.Cross(ey)
+	j := ey.Dot(p3.subtract(p1))
+
+	// The rods have the same length, which simplifies the trilateration
+	x := d / 2
+	y := (i*i+j*j)/(2*j) - i*x/j
+	z := math.Sqrt(k.rodLength*k.rodLength - x*x - y*y)
+
+	// The effector hangs below the carriages
+	position := p1.Add(ex.scale(x)).Add(ey.scale(y))
+	if ez.Z > 0 {
+		return position.subtract(ez.scale(z))
+	}
+	return position.Add(ez.scale(z))
+}
+
+// Get the maximum velocity of each carriage
+func (k *DeltaKinematics) getMaxMotorVelocity() Vector3d {
+	return k.maxMotorVelocity
+}
+
+// Get the maximum acceleration of each carriage
+func (k *DeltaKinematics) getMaxMotorAcceleration() Vector3d {
+	return k.maxMotorAcceleration
+}
@@ -0,0 +1,44 @@
+package main
+
+import "fmt"
+
+// Set a user output (M62, M63, M64, M65)
+type DigitalOutput struct {
+	index        int
+	on           bool
+	synchronized bool
+}
+
+// Create a new digital output command. Synchronized outputs change with the
+// start of the next movement, the others change as soon as they are reached.
+func newDigitalOutput(index int, on bool, synchronized bool) *DigitalOutput {
+	return &DigitalOutput{index: index, on: on, synchronized: synchronized}
+}
+
+// Get the output index
+func (d *DigitalOutput) getIndex() int {
+	return d.index
+}
+
+// Get the output state
+func (d *DigitalOutput) getOn() bool {
+	return d.on
+}
+
+// Check if the output is synchronized with the motion
+func (d *DigitalOutput) isSynchronized() bool {
+	return d.synchronized
+}
+
+// Outputs are passed through with the motion
+func (d *DigitalOutput) requiresFullStop() bool {
+	return false
+}
+
+// Return a string representation of the digital output
+func (d *DigitalOutput) String() string {
+	if d.synchronized {
+		return fmt.Sprintf("Output:      P%d  On: %t  (synchronized)", d.index, d.on)
+	}
+	return fmt.Sprintf("Output:      P%d  On: %t", d.index, d.on)
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestDigitalOutputs(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"M62 P1", "M65 P3"})
+
+	outputs := getCommands[*DigitalOutput](planner)
+	if len(outputs) != 2 {
+		t.Fatalf("got %d outputs, expected 2", len(outputs))
+	}
+	if outputs[0].getIndex() != 1 || !outputs[0].getOn() || !outputs[0].isSynchronized() {
+		t.Errorf("got %v, expected P1 on synchronized", outputs[0])
+	}
+	if outputs[1].getIndex() != 3 || outputs[1].getOn() || outputs[1].isSynchronized() {
+		t.Errorf("got %v, expected P3 off", outputs[1])
+	}
+}
+
+func TestDigitalOutputRequiresP(t *testing.T) {
+	// The P of the path control must not select the output
+	commands, diagnostics := parseWithDiagnostics([]string{"G64 P2", "M64"})
+
+	checkDiagnosticLines(t, diagnostics, 2)
+	if hasCommand(commands, "M64") {
+		t.Errorf("M64 without P was parsed")
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "fmt"
+
+// Pause the motion for a given duration
+type Dwell struct {
+	duration float64
+}
+
+// Create a new dwell, the duration is in seconds
+func newDwell(duration float64) *Dwell {
+	return &Dwell{duration: duration}
+}
+
+// Get the duration
+func (d *Dwell) getDuration() float64 {
+	return d.duration
+}
+
+// Return a string representation of the dwell
+func (d *Dwell) String() string {
+	return fmt.Sprintf("Dwell:       %7.3f s", d.duration)
+}
+
+// The machine must be stopped to dwell
+func (d *Dwell) requiresFullStop() bool {
+	return true
+}
@@ -0,0 +1,59 @@
+package main
+
+import "fmt"
+
+// Get the length of the path of the nozzle along a movement. The length of
+// the linear movements without cartesian motion is the one of the other axes.
+func getNozzlePathLength(movement Movement) float64 {
+	if _, ok := movement.(*LinearMovement); ok {
+		return movement.getEndPosition().subtract(movement.getStartPosition()).length()
+	}
+	return movement.getLength()
+}
+
+// Check if a movement moves the nozzle while extruding
+func (m *MotionPlanner) isExtruding(movement Movement) bool {
+	displacement := movement.getEndAxisPosition().subtract(movement.getStartAxisPosition())
+	return displacement.get(EAxis) != 0 && getNozzlePathLength(movement) > 0
+}
+
+// Get the extruder velocity at a nozzle velocity and acceleration along a
+// movement. The filament follows the nozzle in proportion, and the pressure
+// advance adds a velocity proportional to the acceleration so the pressure in
+// the nozzle builds up and drops with the flow.
+func (m *MotionPlanner) getExtruderVelocity(movement Movement, velocity float64, acceleration float64) float64 {
+	if !m.isExtruding(movement) {
+		return 0
+	}
+
+	displacement := movement.getEndAxisPosition().subtract(movement.getStartAxisPosition())
+	ratio := displacement.get(EAxis) / getNozzlePathLength(movement)
+
+	// Retracting while moving does not need the pressure advance
+	if ratio < 0 {
+		return ratio * velocity
+	}
+
+	return ratio * (velocity + m.machine_configuration.pressureAdvance*acceleration)
+}
+
+// Return a string representation of the extruder velocity at the end of the
+// acceleration, while cruising and at the start of the deceleration of a movement
+func (m *MotionPlanner) extruderVelocityString(movement Movement) string {
+	acceleration := m.getMaxAcceleration(movement)
+	target := movement.getTargetVelocity()
+
+	accelerating := 0.0
+	if target > movement.getStartVelocity() {
+		accelerating = acceleration
+	}
+	decelerating := 0.0
+	if target > movement.getEndVelocity() {
+		decelerating = -acceleration
+	}
+
+	return fmt.Sprintf("E: %7.3f -> %7.3f -> %7.3f m/s",
+		m.getExtruderVelocity(movement, target, accelerating),
+		m.getExtruderVelocity(movement, target, 0),
+		m.getExtruderVelocity(movement, target, decelerating))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Plan lines of the Marlin dialect with extruder only movements limited to 5
+// units/s and 50 units/s², and a pressure advance of 0.05 s
+func planExtruderLines(lines []string) *MotionPlanner {
+	configuration := defaultMachineConfiguration()
+	configuration.setExtruder(5, 50, 0.05)
+	planner := newMotionPlanner(configuration)
+	planner.fromParsedGcode(parseLines(newMarlinDialect(), lines))
+	return planner
+}
+
+func TestExtruderPrintMoveLimits(t *testing.T) {
+	planner := planExtruderLines([]string{"G1 X10 E1 F1200", "G1 E-2 F1200"})
+	movements := planner.commandList.GetMovementList()
+
+	// The nozzle moves while extruding, the extruder only limits do not apply
+	print := movements[0]
+	planner.limitVelocity(print)
+	checkFloat(t, "print velocity", print.getTargetVelocity(), 20)
+	if acceleration := planner.getMaxAcceleration(print); acceleration <= 50 {
+		t.Errorf("got a print acceleration of %g, expected more than the retract limit", acceleration)
+	}
+
+	retract := movements[1]
+	planner.limitVelocity(retract)
+	checkFloat(t, "retract velocity", retract.getTargetVelocity(), 5)
+	checkFloat(t, "retract acceleration", planner.getMaxAcceleration(retract), 50)
+}
+
+func TestExtruderVelocity(t *testing.T) {
+	planner := planExtruderLines([]string{"G1 X10 E1 F1200", "G1 X20 E0.5"})
+	movements := planner.commandList.GetMovementList()
+
+	// The filament follows the nozzle, with the pressure advance while accelerating
+	checkFloat(t, "cruising", planner.getExtruderVelocity(movements[0], 20, 0), 2)
+	checkFloat(t, "accelerating", planner.getExtruderVelocity(movements[0], 20, 100), 2.5)
+	// Retracting while moving has no pressure advance
+	checkFloat(t, "retracting", planner.getExtruderVelocity(movements[1], 20, 100), -1)
+}
+
+func TestExtruderPositionReset(t *testing.T) {
+	// The absolute E words restart from the position set by G92
+	planner := planExtruderLines([]string{"M82", "G1 X10 E5 F1200", "G92 E0", "G1 X20 E2", "M83", "G1 X30 E1"})
+
+	var extrusions []float64
+	for _, movement := range planner.commandList.GetMovementList() {
+		extrusions = append(extrusions, movement.getEndAxisPosition().subtract(movement.getStartAxisPosition()).get(EAxis))
+	}
+	expected := []float64{5, 2, 1}
+	if len(extrusions) != len(expected) {
+		t.Fatalf("got extrusions %v, expected %v", extrusions, expected)
+	}
+	for i := range expected {
+		checkFloat(t, "extrusion", extrusions[i], expected[i])
+	}
+}
+
+func TestExtruderAlongArcs(t *testing.T) {
+	// Half a circle of radius 5, then a full circle ending where it started
+	planner := planExtruderLines([]string{"G2 X10 Y0 I5 J0 E5 F1200", "G2 X10 Y0 I-5 J0 E10"})
+	movements := planner.commandList.GetMovementList()
+	if len(movements) != 2 {
+		t.Fatalf("got movements %v, expected 2", movements)
+	}
+
+	// The filament follows the length of the path, not the chord
+	checkFloat(t, "half circle", planner.getExtruderVelocity(movements[0], 20, 0), 20*5/(5*math.Pi))
+	checkFloat(t, "full circle", planner.getExtruderVelocity(movements[1], 20, 0), 20*5/(10*math.Pi))
+	checkFloat(t, "extruder position", movements[1].getEndAxisPosition().get(EAxis), 10)
+}
@@ -0,0 +1,45 @@
+package main
+
+import "fmt"
+
+// Number of points along a movement where the joint limits are checked
+const jointLimitSamples = 8
+
+// Check if a movement is programmed with the tool center point control on a
+// five axis machine
+func (m *MotionPlanner) isToolCenterPoint(movement Movement) bool {
+	toolCenterPoint := m.toolCenterPoint[movement]
+	return toolCenterPoint != nil && toolCenterPoint.isEnabled() && m.machine_configuration.fiveAxisKinematics != nil
+}
+
+// Get the position of the linear joints at a fraction of a movement programmed
+// with the tool center point control
+func (m *MotionPlanner) getJointPosition(movement Movement, toolCenterPoint *ToolCenterPoint, fraction float64) (Vector3d, AxisPosition) {
+	orientation := movement.getStartAxisPosition().interpolate(movement.getEndAxisPosition(), fraction)
+	joints := m.machine_configuration.fiveAxisKinematics.inverse(movement.getPositionAt(fraction), orientation, toolCenterPoint.getToolLength())
+	return joints, orientation
+}
+
+// Check that the joints stay within their travel along a movement programmed
+// with the tool center point control
+func (m *MotionPlanner) checkJointLimits(movement Movement, toolCenterPoint *ToolCenterPoint) error {
+	for i := 0; i <= jointLimitSamples; i++ {
+		joints, orientation := m.getJointPosition(movement, toolCenterPoint, float64(i)/jointLimitSamples)
+		if err := m.machine_configuration.fiveAxisKinematics.checkLimits(joints, orientation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return a string representation of the joint positions at the end of a movement
+func (m *MotionPlanner) jointPositionString(movement Movement) string {
+	toolCenterPoint := m.toolCenterPoint[movement]
+	joints, orientation := m.getJointPosition(movement, toolCenterPoint, 1)
+	description := fmt.Sprintf("Joints: X%.3f Y%.3f Z%.3f A%.3f B%.3f C%.3f", joints.X, joints.Y, joints.Z, orientation.get(AAxis), orientation.get(BAxis), orientation.get(CAxis))
+
+	if err := m.checkJointLimits(movement, toolCenterPoint); err != nil {
+		return description + " " + err.Error()
+	}
+	return description
+}
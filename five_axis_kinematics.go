@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Five axis machine configuration enum
+type FiveAxisConfiguration int
+
+const (
+	// Table tilting around X (A) carrying a rotary table around Z (C)
+	TableTableAC FiveAxisConfiguration = iota
+	// Table tilting around Y (B) carrying a rotary table around Z (C)
+	TableTableBC
+	// Head rotating around Z (C) carrying a spindle tilting around X (A)
+	HeadHeadAC
+	// Head rotating around Z (C) carrying a spindle tilting around Y (B)
+	HeadHeadBC
+)
+
+// Five axis kinematics converting the tool tip position and orientation to
+// the position of the linear joints. The rotary joints are the A, B and C
+// axes, in degrees, with positive angles turning the table or the head
+// counter clockwise when looking down the axis.
+type FiveAxisKinematics struct {
+	configuration FiveAxisConfiguration
+
+	// Table: point on the tilting axis
+	// Head: offset from the tilting axis to the tool tip when the head is not rotated
+	tiltOffset Vector3d
+
+	// Table: point on the rotary table axis when the table is not tilted
+	// Head: offset from the controlled point to the tilting axis when the head is not rotated
+	rotaryOffset Vector3d
+
+	// Travel of the linear joints and of the tilting axis, in degrees, checked
+	// when limited. The rotary axis turns without limit.
+	limited  bool
+	jointMin Vector3d
+	jointMax Vector3d
+	tiltMin  float64
+	tiltMax  float64
+}
+
+// Create a new five axis kinematics
+func newFiveAxisKinematics(configuration FiveAxisConfiguration, tiltOffset Vector3d, rotaryOffset Vector3d) *FiveAxisKinematics {
+	return &FiveAxisKinematics{configuration: configuration, tiltOffset: tiltOffset, rotaryOffset: rotaryOffset}
+}
+
+// Set the travel of the linear joints and of the tilting axis, in degrees
+func (k *FiveAxisKinematics) setLimits(jointMin Vector3d, jointMax Vector3d, tiltMin float64, tiltMax float64) {
+	k.limited = true
+	k.jointMin = jointMin
+	k.jointMax = jointMax
+	k.tiltMin = tiltMin
+	k.tiltMax = tiltMax
+}
+
+// Get the tilting axis and angle, in radians, for the orientation
+func (k *FiveAxisKinematics) getTilt(orientation AxisPosition) (Axis, float64) {
+	if k.configuration == TableTableAC || k.configuration == HeadHeadAC {
+		return XAxis, orientation.get(AAxis) * math.Pi / 180
+	}
+	return YAxis, orientation.get(BAxis) * math.Pi / 180
+}
+
+// Check if the rotary axes move the table
+func (k *FiveAxisKinematics) isTableTable() bool {
+	return k.configuration == TableTableAC || k.configuration == TableTableBC
+}
+
+// Get the offset from the tilting axis to the tool tip of a head when it is
+// not rotated. The tool sticks out of the head along -Z.
+func (k *FiveAxisKinematics) getToolOffset(toolLength float64) Vector3d {
+	return k.tiltOffset.Add(Vector3d{X: 0, Y: 0, Z: -toolLength})
+}
+
+// Get the position of the linear joints for a tool tip position in the
+// coordinates of the part. The joints give the position of the spindle nose,
+// the tool length above the tool tip.
+func (k *FiveAxisKinematics) inverse(tip Vector3d, orientation AxisPosition, toolLength float64) Vector3d {
+	tiltAxis, tilt := k.getTilt(orientation)
+	rotary := orientation.get(CAxis) * math.Pi / 180
+
+	if k.isTableTable() {
+		// Turn the point of the part with the rotary table, then with the tilting table
+		onRotary := tip.subtract(k.rotaryOffset).rotate(ZAxis, rotary).Add(k.rotaryOffset)
+		return onRotary.subtract(k.tiltOffset).rotate(tiltAxis, tilt).Add(k.tiltOffset).Add(Vector3d{X: 0, Y: 0, Z: toolLength})
+	}
+
+	// Move the controlled point so the rotated tool tip lands on the position
+	tool := k.rotaryOffset.Add(k.getToolOffset(toolLength).rotate(tiltAxis, tilt)).rotate(ZAxis, rotary)
+	return tip.subtract(tool)
+}
+
+// Get the tool tip position in the coordinates of the part for the position
+// of the linear joints
+func (k *FiveAxisKinematics) forward(joints Vector3d, orientation AxisPosition, toolLength float64) Vector3d {
+	tiltAxis, tilt := k.getTilt(orientation)
+	rotary := orientation.get(CAxis) * math.Pi / 180
+
+	if k.isTableTable() {
+		tip := joints.subtract(Vector3d{X: 0, Y: 0, Z: toolLength})
+		onRotary := tip.subtract(k.tiltOffset).rotate(tiltAxis, -tilt).Add(k.tiltOffset)
+		return onRotary.subtract(k.rotaryOffset).rotate(ZAxis, -rotary).Add(k.rotaryOffset)
+	}
+
+	tool := k.rotaryOffset.Add(k.getToolOffset(toolLength).rotate(tiltAxis, tilt)).rotate(ZAxis, rotary)
+	return joints.Add(tool)
+}
+
+// Check that the linear joints and the tilting axis are within their travel
+func (k *FiveAxisKinematics) checkLimits(joints Vector3d, orientation AxisPosition) error {
+	if !k.limited {
+		return nil
+	}
+
+	tiltAxis, _ := k.getTilt(orientation)
+	tiltName := AAxis
+	if tiltAxis == YAxis {
+		tiltName = BAxis
+	}
+	if tilt := orientation.get(tiltName); tilt < k.tiltMin || tilt > k.tiltMax {
+		return fmt.Errorf("%s%g is outside the tilting range %g to %g", tiltName, tilt, k.tiltMin, k.tiltMax)
+	}
+
+	min := k.jointMin
+	max := k.jointMax
+	if joints.X < min.X || joints.Y < min.Y || joints.Z < min.Z || joints.X > max.X || joints.Y > max.Y || joints.Z > max.Z {
+		return fmt.Errorf("joints %v are outside their travel", joints)
+	}
+	return nil
+}
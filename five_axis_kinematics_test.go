@@ -0,0 +1,143 @@
+package main
+
+import "testing"
+
+// Orientations of the tool, in degrees
+func newOrientation(a float64, b float64, c float64) AxisPosition {
+	return AxisPosition{}.with(AAxis, a).with(BAxis, b).with(CAxis, c)
+}
+
+func TestFiveAxisRoundTrip(t *testing.T) {
+	tiltOffset := Vector3d{X: 1, Y: -2, Z: 50}
+	rotaryOffset := Vector3d{X: 3, Y: 4, Z: 20}
+	tips := []Vector3d{{}, {X: 10, Y: -5, Z: 2}, {X: -30, Y: 12.5, Z: 40}}
+	orientations := []AxisPosition{newOrientation(0, 0, 0), newOrientation(30, 30, 45), newOrientation(-90, -60, 270)}
+
+	for _, configuration := range []FiveAxisConfiguration{TableTableAC, TableTableBC, HeadHeadAC, HeadHeadBC} {
+		kinematics := newFiveAxisKinematics(configuration, tiltOffset, rotaryOffset)
+		for _, tip := range tips {
+			for _, orientation := range orientations {
+				for _, toolLength := range []float64{0, 75} {
+					joints := kinematics.inverse(tip, orientation, toolLength)
+					checkVector(t, "tool tip", kinematics.forward(joints, orientation, toolLength), tip)
+				}
+			}
+		}
+	}
+}
+
+func TestFiveAxisTableTable(t *testing.T) {
+	// A table tilting around X at Y0 Z0
+	kinematics := newFiveAxisKinematics(TableTableAC, Vector3d{}, Vector3d{})
+
+	// A point of the part on the Y axis goes up when the table tilts by A90
+	checkVector(t, "joints", kinematics.inverse(Vector3d{Y: 10}, newOrientation(90, 0, 0), 0), Vector3d{Z: 10})
+	// The spindle nose stays the tool length above the tool tip
+	checkVector(t, "joints", kinematics.inverse(Vector3d{Y: 10}, newOrientation(90, 0, 0), 50), Vector3d{Z: 60})
+}
+
+func TestFiveAxisHeadHead(t *testing.T) {
+	// A head tilting around Y, the tool tip 10 below the tilting axis
+	kinematics := newFiveAxisKinematics(HeadHeadBC, Vector3d{Z: -10}, Vector3d{})
+
+	// Upright, the controlled point is above the tool tip by the head and the tool
+	checkVector(t, "joints", kinematics.inverse(Vector3d{}, newOrientation(0, 0, 0), 40), Vector3d{Z: 50})
+	// Tilted by B90, the tool sticks out along -X
+	checkVector(t, "joints", kinematics.inverse(Vector3d{}, newOrientation(0, 90, 0), 40), Vector3d{X: 50})
+}
+
+func TestFiveAxisLimits(t *testing.T) {
+	kinematics := newFiveAxisKinematics(TableTableAC, Vector3d{}, Vector3d{})
+	if err := kinematics.checkLimits(Vector3d{X: 1000}, newOrientation(120, 0, 0)); err != nil {
+		t.Errorf("got %v without limits", err)
+	}
+
+	kinematics.setLimits(Vector3d{X: -100, Y: -100, Z: -50}, Vector3d{X: 100, Y: 100, Z: 50}, -30, 110)
+	// The rotary axis is not limited
+	if err := kinematics.checkLimits(Vector3d{X: 10}, newOrientation(90, 0, 720)); err != nil {
+		t.Errorf("got %v within the limits", err)
+	}
+	if err := kinematics.checkLimits(Vector3d{X: 10}, newOrientation(-45, 0, 0)); err == nil {
+		t.Errorf("A-45 is accepted below the tilting range")
+	}
+	if err := kinematics.checkLimits(Vector3d{Z: 60}, newOrientation(0, 0, 0)); err == nil {
+		t.Errorf("Z60 is accepted outside the travel")
+	}
+}
+
+// Create a machine with a table tilting around X, without a tool length offset
+// for T1 and 50 long for T2
+func newFiveAxisConfiguration() *MachineConfiguration {
+	configuration := defaultMachineConfiguration()
+	kinematics := newFiveAxisKinematics(TableTableAC, Vector3d{}, Vector3d{})
+	kinematics.setLimits(Vector3d{X: -100, Y: -100, Z: -100}, Vector3d{X: 100, Y: 100, Z: 100}, -30, 110)
+	configuration.setFiveAxisKinematics(kinematics)
+	configuration.setToolLength(2, 50)
+	return configuration
+}
+
+func TestToolCenterPointToolLength(t *testing.T) {
+	planner := planLines(newFiveAxisConfiguration(), []string{"T1 M6", "G43.4 H2", "G43.4", "T2 M6", "G43.4", "G49"})
+
+	var lengths []float64
+	for _, toolCenterPoint := range getCommands[*ToolCenterPoint](planner) {
+		lengths = append(lengths, toolCenterPoint.getToolLength())
+	}
+	// H selects the tool, the current tool is used without it. The startup
+	// code of the dialect cancels the tool center point control first.
+	expected := []float64{0, 50, 0, 50, 0}
+	if len(lengths) != len(expected) {
+		t.Fatalf("got tool lengths %v, expected %v", lengths, expected)
+	}
+	for i := range expected {
+		checkFloat(t, "tool length", lengths[i], expected[i])
+	}
+}
+
+func TestToolCenterPointJointPositions(t *testing.T) {
+	planner := planLines(newFiveAxisConfiguration(), []string{"G43.4 H2", "G1 Y10 A90 F600", "G49", "G1 Y0 A0"})
+	planner.toolCenterPoint = make(map[Movement]*ToolCenterPoint)
+	movements := planner.commandList.GetMovementList()
+	for i, toolCenterPoint := range planner.commandList.GetToolCenterPointList() {
+		planner.toolCenterPoint[movements[i]] = toolCenterPoint
+	}
+
+	if !planner.isToolCenterPoint(movements[0]) || planner.isToolCenterPoint(movements[1]) {
+		t.Fatalf("the tool center point control is not on for the first movement only")
+	}
+	joints, _ := planner.getJointPosition(movements[0], planner.toolCenterPoint[movements[0]], 1)
+	checkVector(t, "joints", joints, Vector3d{Z: 60})
+	if description := planner.jointPositionString(movements[0]); description != "Joints: X0.000 Y0.000 Z60.000 A90.000 B0.000 C0.000" {
+		t.Errorf("got %q", description)
+	}
+
+	// Tilting the part 10 away from the axis by A-45 goes past the tilting range
+	if err := planner.checkJointLimits(newTestLinearMovement(Vector3d{Y: 10}, newOrientation(-45, 0, 0)), planner.toolCenterPoint[movements[0]]); err == nil {
+		t.Errorf("a movement past the tilting range is accepted")
+	}
+}
+
+// Create a linear movement from the origin to a position and orientation
+func newTestLinearMovement(end Vector3d, orientation AxisPosition) *LinearMovement {
+	movement := newLinearMovement(end, 10)
+	movement.setStartAxisPosition(newAxisPosition(Vector3d{}))
+	movement.setEndAxisPosition(orientation.withCartesian(end))
+	return movement
+}
+
+func TestLintJointLimits(t *testing.T) {
+	linter := newGCodeLinter(newLinuxCNCDialect(), newFiveAxisConfiguration())
+	// The same tip positions are within the travel without the tool center point control
+	linter.checkCommands(parseLines(newLinuxCNCDialect(), []string{"G1 Y90 A90 F600", "G43.4 H2", "G1 Y90 A90", "G1 Y0 A0"}))
+
+	var lines []int
+	for _, finding := range linter.findings {
+		if finding.Check == "joint-limits" {
+			lines = append(lines, finding.Line)
+		}
+	}
+	// The part is lifted 140 above the table axis, and the movement back starts there
+	if len(lines) != 2 || lines[0] != 3 || lines[1] != 4 {
+		t.Errorf("got joint limit findings on lines %v, expected 3 and 4", lines)
+	}
+}
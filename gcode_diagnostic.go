@@ -0,0 +1,39 @@
+package main
+
+import "fmt"
+
+// Diagnostic of the parser on a line of a program. The line counts from 1,
+// 0 for the lines not read from a program, and the N number is -1 for the
+// lines without one.
+type GCodeDiagnostic struct {
+	file       string
+	line       int
+	lineNumber int
+	message    string
+
+	// The line uses a code or a word the dialect does not support
+	unsupported bool
+
+	// The line is parsed anyway
+	warning bool
+}
+
+// Get the message of the diagnostic
+func (d GCodeDiagnostic) getMessage() string {
+	return d.message
+}
+
+// Return a string representation of the diagnostic
+func (d GCodeDiagnostic) String() string {
+	message := d.message
+	if d.warning {
+		message = "warning: " + message
+	}
+	if d.lineNumber >= 0 {
+		message = fmt.Sprintf("N%d: %s", d.lineNumber, message)
+	}
+	if d.line > 0 {
+		message = lineLocation(d.file, d.line) + ": " + message
+	}
+	return message
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unknown word policy enum (UnknownWordError, UnknownWordWarn, UnknownWordIgnore)
+type UnknownWordPolicy int
+
+const (
+	// Reject the line
+	UnknownWordError UnknownWordPolicy = iota
+	// Report the word and parse the line without it
+	UnknownWordWarn
+	// Parse the line without the word
+	UnknownWordIgnore
+)
+
+// Words setting a modal value for the following commands, accepted on any line
+const modalWords = "FST"
+
+// Profile of the G-code dialect emitted by a sender or a CAM post processor
+type GCodeDialect struct {
+	name string
+
+	// Codes accepted by the dialect, with the words each of them accepts
+	allowedParams map[string]map[string]bool
+
+	// Motion mode at the start of a program, empty when the axis words need a
+	// motion command, and the codes setting the other modal states
+	motionMode  string
+	startupCode string
+
+	unknownWords UnknownWordPolicy
+
+	// G28 homes the axes (Marlin) instead of returning them to their
+	// reference position. Without axis words G28 returns all the axes, or
+	// none of them on the dialects returning only the named axes (Fanuc).
+	g28Homes          bool
+	g28ReturnsAllAxes bool
+
+	// The dwell P words are in milliseconds (Fanuc) instead of seconds
+	dwellMilliseconds bool
+}
+
+// Get the codes of the planner with the words each of them accepts
+func plannerParams() map[string]map[string]bool {
+	var cannedCycleParams = map[string]bool{"X": true, "Y": true, "Z": true, "R": true, "Q": true, "P": true, "L": true, "F": true}
+	var axisParams = map[string]bool{"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true}
+
+	return map[string]map[string]bool{
+		"G0":    {"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true, "E": true, "F": true},
+		"G1":    {"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true, "E": true, "F": true, "S": true},
+		"G2":    {"X": true, "Y": true, "Z": true, "I": true, "J": true, "K": true, "R": true, "P": true, "E": true, "F": true, "S": true},
+		"G3":    {"X": true, "Y": true, "Z": true, "I": true, "J": true, "K": true, "R": true, "P": true, "E": true, "F": true, "S": true},
+		"G73":   cannedCycleParams,
+		"G80":   {},
+		"G81":   cannedCycleParams,
+		"G82":   cannedCycleParams,
+		"G83":   cannedCycleParams,
+		"G84":   cannedCycleParams,
+		"G85":   cannedCycleParams,
+		"G86":   cannedCycleParams,
+		"G87":   {"X": true, "Y": true, "Z": true, "R": true, "I": true, "J": true, "K": true, "L": true, "F": true},
+		"G88":   cannedCycleParams,
+		"G89":   cannedCycleParams,
+		"G38.2": {"X": true, "Y": true, "Z": true, "F": true},
+		"G38.3": {"X": true, "Y": true, "Z": true, "F": true},
+		"G38.4": {"X": true, "Y": true, "Z": true, "F": true},
+		"G38.5": {"X": true, "Y": true, "Z": true, "F": true},
+		"G33":   {"X": true, "Y": true, "Z": true, "K": true},
+		"G33.1": {"X": true, "Y": true, "Z": true, "K": true},
+		"G76":   {"Z": true, "P": true, "I": true, "J": true, "R": true, "K": true, "Q": true, "H": true},
+		"G98":   {},
+		"G99":   {},
+		"G61":   {},
+		"G61.1": {},
+		"G64":   {"P": true, "Q": true},
+		"G43":   {"H": true},
+		"G43.4": {"H": true},
+		"G49":   {},
+		"G93":   {},
+		"G94":   {},
+		"G95":   {},
+		"G4":    {"P": true},
+		"G17":   {},
+		"G18":   {},
+		"G19":   {},
+		"G20":   {},
+		"G21":   {},
+		"G28":   axisParams,
+		"G30":   axisParams,
+		"G40":   {},
+		"G54":   {},
+		"G55":   {},
+		"G56":   {},
+		"G57":   {},
+		"G58":   {},
+		"G59":   {},
+		"G90":   {},
+		"G91":   {},
+		"G90.1": {},
+		"G91.1": {},
+		"G92":   {"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true, "E": true},
+		"M0":    {},
+		"M1":    {},
+		"M2":    {},
+		"M30":   {},
+		"M3":    {"S": true},
+		"M4":    {"S": true},
+		"M5":    {},
+		"M6":    {"T": true},
+		"M7":    {},
+		"M8":    {},
+		"M9":    {},
+		"M48":   {},
+		"M49":   {},
+		"M62":   {"P": true},
+		"M63":   {"P": true},
+		"M64":   {"P": true},
+		"M65":   {"P": true},
+		"M82":   {},
+		"M83":   {},
+	}
+}
+
+// Keep the codes of a dialect from the codes of the planner
+func selectParams(codes ...string) map[string]map[string]bool {
+	params := plannerParams()
+	selected := make(map[string]map[string]bool)
+	for _, code := range codes {
+		selected[code] = params[code]
+	}
+	return selected
+}
+
+// Remove words from the codes of a dialect
+func removeWords(params map[string]map[string]bool, letters string) map[string]map[string]bool {
+	removed := make(map[string]map[string]bool)
+	for code, words := range params {
+		removed[code] = make(map[string]bool)
+		for letter, allowed := range words {
+			if !strings.Contains(letters, letter) {
+				removed[code][letter] = allowed
+			}
+		}
+	}
+	return removed
+}
+
+// Create the LinuxCNC dialect, the default one, accepting every code of the planner
+func newLinuxCNCDialect() *GCodeDialect {
+	return &GCodeDialect{
+		name:              "LinuxCNC",
+		allowedParams:     plannerParams(),
+		startupCode:       "G17 G21 G40 G49 G54 G90 G94 G98",
+		unknownWords:      UnknownWordError,
+		g28ReturnsAllAxes: true,
+	}
+}
+
+// Create the grbl dialect. Axis words alone continue a rapid at power on.
+func newGrblDialect() *GCodeDialect {
+	return &GCodeDialect{
+		name: "grbl",
+		allowedParams: removeWords(selectParams("G0", "G1", "G2", "G3", "G4", "G17", "G18", "G19", "G20", "G21", "G28", "G30",
+			"G38.2", "G38.3", "G38.4", "G38.5", "G40", "G49", "G54", "G55", "G56", "G57", "G58", "G59", "G61", "G80",
+			"G90", "G91", "G91.1", "G92", "G93", "G94", "M0", "M1", "M2", "M30", "M3", "M4", "M5", "M7", "M8", "M9"), "EUVW"),
+		motionMode:        "G0",
+		startupCode:       "G17 G21 G54 G90 G94",
+		unknownWords:      UnknownWordError,
+		g28ReturnsAllAxes: true,
+	}
+}
+
+// Create the Marlin dialect of the 3D printers, with the temperature codes.
+// Marlin reports the unknown commands and goes on.
+func newMarlinDialect() *GCodeDialect {
+	allowedParams := selectParams("G0", "G1", "G2", "G3", "G4", "G20", "G21", "G28", "G90", "G91", "G92",
+		"M0", "M1", "M3", "M4", "M5", "M7", "M8", "M9", "M82", "M83")
+	for _, code := range []string{"M104", "M109", "M140", "M190"} {
+		allowedParams[code] = map[string]bool{"S": true}
+	}
+
+	return &GCodeDialect{
+		name:          "Marlin",
+		allowedParams: allowedParams,
+		startupCode:   "G21 G90 M82",
+		unknownWords:  UnknownWordWarn,
+		g28Homes:      true,
+	}
+}
+
+// Create the Fanuc dialect, with the canned cycles and the retract to the initial level
+func newFanucDialect() *GCodeDialect {
+	return &GCodeDialect{
+		name: "Fanuc",
+		allowedParams: removeWords(selectParams("G0", "G1", "G2", "G3", "G4", "G17", "G18", "G19", "G20", "G21", "G28", "G30",
+			"G40", "G43", "G49", "G54", "G55", "G56", "G57", "G58", "G59", "G61", "G64",
+			"G73", "G80", "G81", "G82", "G83", "G84", "G85", "G86", "G87", "G88", "G89",
+			"G90", "G91", "G92", "G94", "G95", "G98", "G99",
+			"M0", "M1", "M2", "M30", "M3", "M4", "M5", "M6", "M7", "M8", "M9"), "E"),
+		motionMode:        "G0",
+		startupCode:       "G17 G21 G40 G49 G54 G90 G94 G98",
+		unknownWords:      UnknownWordError,
+		dwellMilliseconds: true,
+	}
+}
+
+// Get a dialect by its name, not case sensitive
+func getGCodeDialect(name string) (*GCodeDialect, error) {
+	switch strings.ToLower(name) {
+	case "linuxcnc":
+		return newLinuxCNCDialect(), nil
+	case "grbl":
+		return newGrblDialect(), nil
+	case "marlin":
+		return newMarlinDialect(), nil
+	case "fanuc":
+		return newFanucDialect(), nil
+	}
+	return nil, fmt.Errorf("unknown G-code dialect: %s", name)
+}
+
+// Get the name of the dialect
+func (d *GCodeDialect) getName() string {
+	return d.name
+}
+
+// Set how the unknown words are treated
+func (d *GCodeDialect) setUnknownWordPolicy(policy UnknownWordPolicy) {
+	d.unknownWords = policy
+}
+
+// Check if the dialect accepts a code
+func (d *GCodeDialect) accepts(code string) bool {
+	_, ok := d.allowedParams[code]
+	return ok
+}
+
+// Check if a code accepts a word
+func (d *GCodeDialect) acceptsWord(code string, letter string) bool {
+	return d.allowedParams[code][letter]
+}
+
+// Get the scale of a word of a code from the units of the planner to the
+// units of the dialect
+func (d *GCodeDialect) getWordScale(code string, letter string) float64 {
+	if d.dwellMilliseconds && letter == "P" && (code == "G4" || isCannedCycle(code)) {
+		return 1000
+	}
+	return 1
+}
+
+// Translate a code to the command of the planner, given the words it
+// accepts, and convert the words to the units of the planner. Return false
+// if the code does nothing in the dialect.
+func (d *GCodeDialect) translate(code string, params map[string]float64) (string, bool) {
+	for letter := range params {
+		params[letter] /= d.getWordScale(code, letter)
+	}
+	if code != "G28" {
+		return code, true
+	}
+	if d.g28Homes {
+		return "Home", true
+	}
+	return code, len(params) > 0 || d.g28ReturnsAllAxes
+}
+
+// Get the code of the dialect for a command of the planner, given its words.
+// Return false if the dialect cannot express the command.
+func (d *GCodeDialect) untranslate(command string, params map[string]float64) (string, bool) {
+	switch command {
+	case "Home":
+		return "G28", d.g28Homes
+	case "G28":
+		return command, !d.g28Homes && (len(params) > 0 || d.g28ReturnsAllAxes)
+	}
+	return command, d.accepts(command)
+}
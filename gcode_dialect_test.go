@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+// Parse lines with a dialect and collect the diagnostics
+func parseDialectWithDiagnostics(dialect *GCodeDialect, lines []string) ([]GCodeCommand, []GCodeDiagnostic) {
+	parser := newGCodeParser()
+	parser.setDialect(dialect)
+	var diagnostics []GCodeDiagnostic
+	parser.setReporter(func(diagnostic GCodeDiagnostic) {
+		diagnostics = append(diagnostics, diagnostic)
+	})
+	return parser.fromString(lines), diagnostics
+}
+
+func TestDialectUnsupportedCodes(t *testing.T) {
+	// grbl has no canned cycles, the line is an error
+	commands, diagnostics := parseDialectWithDiagnostics(newGrblDialect(), []string{"G81 X1 Z-1 R1", "G1 X2 F100"})
+	checkDiagnosticLines(t, diagnostics, 1)
+	if !diagnostics[0].unsupported || diagnostics[0].warning {
+		t.Errorf("got %v, expected an unsupported code error", diagnostics[0])
+	}
+	if hasCommand(commands, "G81") || !hasCommand(commands, "G1") {
+		t.Errorf("got commands %v, expected only the G1", commands)
+	}
+
+	// Marlin warns and parses the rest of the line
+	commands, diagnostics = parseDialectWithDiagnostics(newMarlinDialect(), []string{"M420 S1 G1 X2 F100"})
+	checkDiagnosticLines(t, diagnostics, 1)
+	if !diagnostics[0].unsupported || !diagnostics[0].warning {
+		t.Errorf("got %v, expected an unsupported code warning", diagnostics[0])
+	}
+	if !hasCommand(commands, "G1") {
+		t.Errorf("got commands %v, expected the G1", commands)
+	}
+}
+
+func TestDialectMotionModeAtPowerOn(t *testing.T) {
+	// Axis words alone continue a rapid in grbl, there is no motion mode in LinuxCNC
+	commands, diagnostics := parseDialectWithDiagnostics(newGrblDialect(), []string{"X1 Y2"})
+	checkDiagnosticLines(t, diagnostics)
+	if !hasCommand(commands, "G0") {
+		t.Errorf("got commands %v, expected a G0", commands)
+	}
+
+	commands, _ = parseDialectWithDiagnostics(newLinuxCNCDialect(), []string{"X1 Y2"})
+	if hasCommand(commands, "G0") || hasCommand(commands, "G1") {
+		t.Errorf("got commands %v, expected no motion", commands)
+	}
+}
+
+func TestDialectReferenceReturn(t *testing.T) {
+	// Marlin homes with G28, grbl returns to the stored position
+	commands, _ := parseDialectWithDiagnostics(newMarlinDialect(), []string{"G28"})
+	if !hasCommand(commands, "Home") {
+		t.Errorf("got commands %v, expected the Marlin G28 to home", commands)
+	}
+
+	commands, _ = parseDialectWithDiagnostics(newGrblDialect(), []string{"G28"})
+	if !hasCommand(commands, "G28") || hasCommand(commands, "Home") {
+		t.Errorf("got commands %v, expected the grbl G28 to return to the reference", commands)
+	}
+}
+
+func TestDialectByName(t *testing.T) {
+	for _, name := range []string{"LinuxCNC", "grbl", "MARLIN", "fanuc"} {
+		if _, err := getGCodeDialect(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := getGCodeDialect("mach3"); err == nil {
+		t.Errorf("got no error for an unknown dialect")
+	}
+}
+
+func TestDialectFanucDwellInMilliseconds(t *testing.T) {
+	commands, diagnostics := parseDialectWithDiagnostics(newFanucDialect(), []string{"G4 P500", "G0 Z5", "G82 Z-1 R1 P250 F100"})
+	checkDiagnosticLines(t, diagnostics)
+
+	planner := newMotionPlanner(defaultMachineConfiguration())
+	planner.fromParsedGcode(commands)
+	dwells := getCommands[*Dwell](planner)
+	if len(dwells) != 2 || dwells[0].getDuration() != 0.5 || dwells[1].getDuration() != 0.25 {
+		t.Fatalf("got dwells %v, expected 0.5 s and 0.25 s", dwells)
+	}
+
+	// The writer converts the dwells back to milliseconds
+	written := checkRoundTrip(t, newFanucDialect(), []string{"G4 P500", "G0 Z5", "G82 Z-1 R1 P250 F100"}, false)
+	if written[0] != "G4 P500" {
+		t.Errorf("got %q, expected the dwell in milliseconds", written[0])
+	}
+}
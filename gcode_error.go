@@ -0,0 +1,60 @@
+package main
+
+import "fmt"
+
+// Error in a G-code program, with the file and the line it happened on
+// counting from 1, its N number, and the subroutine calls that led to it
+type GCodeError struct {
+	file       string
+	line       int
+	lineNumber int
+	message    string
+	stack      []string
+}
+
+// Create a new G-code error, the file is empty for programs not read from a
+// file and the N number is -1 for lines without one
+func newGCodeError(file string, line int, lineNumber int, message string) *GCodeError {
+	return &GCodeError{file: file, line: line, lineNumber: lineNumber, message: message}
+}
+
+// Get the line of the error
+func (e *GCodeError) getLine() int {
+	return e.line
+}
+
+// Add a call that led to the error, the innermost first
+func (e *GCodeError) addCall(call string) {
+	e.stack = append(e.stack, call)
+}
+
+// Get the location of a line, with its file if there is one
+func lineLocation(file string, line int) string {
+	if file == "" {
+		return fmt.Sprintf("line %d", line)
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// Return a string representation of the error
+func (e *GCodeError) Error() string {
+	message := lineLocation(e.file, e.line)
+	if e.lineNumber >= 0 {
+		message += fmt.Sprintf(" (N%d)", e.lineNumber)
+	}
+	message += ": " + e.message
+
+	// Recursive calls from the same line are shown once
+	for i := 0; i < len(e.stack); {
+		count := 1
+		for i+count < len(e.stack) && e.stack[i+count] == e.stack[i] {
+			count++
+		}
+		message += "\n\tcalled from " + e.stack[i]
+		if count > 1 {
+			message += fmt.Sprintf(" (%d times)", count)
+		}
+		i += count
+	}
+	return message
+}
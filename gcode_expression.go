@@ -0,0 +1,338 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Binary operators from the lowest to the highest precedence
+var binaryOperators = [][]string{
+	{"AND", "XOR", "OR"},
+	{"EQ", "NE", "GT", "GE", "LT", "LE"},
+	{"+", "-"},
+	{"*", "/", "MOD"},
+	{"**"},
+}
+
+// Read the values of a G-code line: numbers, parameters, expressions in
+// brackets and functions. Angles of the functions are in degrees.
+type ExpressionReader struct {
+	text       string
+	position   int
+	parameters *GCodeParameters
+}
+
+// Create a new expression reader for a line
+func newExpressionReader(text string, parameters *GCodeParameters) *ExpressionReader {
+	return &ExpressionReader{text: text, parameters: parameters}
+}
+
+// Skip the spaces and check if the end of the line is reached
+func (r *ExpressionReader) atEnd() bool {
+	for r.position < len(r.text) && (r.text[r.position] == ' ' || r.text[r.position] == '\t') {
+		r.position++
+	}
+	return r.position >= len(r.text)
+}
+
+// Get the next character, in upper case, or 0 at the end of the line
+func (r *ExpressionReader) peek() byte {
+	if r.atEnd() {
+		return 0
+	}
+	c := r.text[r.position]
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	return c
+}
+
+// Read an expected character
+func (r *ExpressionReader) expect(c byte) error {
+	if r.peek() != c {
+		return fmt.Errorf("expected '%c' at: %s", c, r.rest())
+	}
+	r.position++
+	return nil
+}
+
+// Get the rest of the line, for the error messages
+func (r *ExpressionReader) rest() string {
+	if r.position >= len(r.text) {
+		return "end of line"
+	}
+	return r.text[r.position:]
+}
+
+// Check if a character is a letter
+func isLetter(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+// Read a value: a number, a parameter, an expression, a function or a signed value
+func (r *ExpressionReader) readRealValue() (float64, error) {
+	c := r.peek()
+	switch {
+	case c == '-' || c == '+':
+		r.position++
+		value, err := r.readRealValue()
+		if c == '-' {
+			value = -value
+		}
+		return value, err
+	case c == '#':
+		r.position++
+		reference, err := r.readReference()
+		if err != nil {
+			return 0, err
+		}
+		return r.parameters.get(reference)
+	case c == '[':
+		return r.readExpression()
+	case isLetter(c):
+		return r.readFunction()
+	case c == '.' || (c >= '0' && c <= '9'):
+		return r.readNumber()
+	}
+	return 0, fmt.Errorf("expected a value at: %s", r.rest())
+}
+
+// Read a number without sign, the spaces between its digits are ignored
+func (r *ExpressionReader) readNumber() (float64, error) {
+	start := r.position
+	digits, end := scanNumber(r.text, start)
+	r.position = end
+
+	value, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number: %s", r.text[start:end])
+	}
+	return value, nil
+}
+
+// Read the reference of a parameter following a #. Named parameters are
+// case insensitive and ignore the spaces.
+func (r *ExpressionReader) readReference() (ParameterReference, error) {
+	if r.peek() == '<' {
+		end := strings.IndexByte(r.text[r.position:], '>')
+		if end < 0 {
+			return ParameterReference{}, fmt.Errorf("unterminated parameter name at: %s", r.rest())
+		}
+		name := strings.ToLower(strings.Join(strings.Fields(r.text[r.position+1:r.position+end]), ""))
+		r.position += end + 1
+		if name == "" {
+			return ParameterReference{}, errors.New("empty parameter name")
+		}
+		return ParameterReference{name: name}, nil
+	}
+
+	value, err := r.readRealValue()
+	if err != nil {
+		return ParameterReference{}, err
+	}
+	index := math.Round(value)
+	if math.Abs(value-index) > 1e-6 {
+		return ParameterReference{}, fmt.Errorf("parameter number %g is not an integer", value)
+	}
+	return ParameterReference{index: int(index)}, nil
+}
+
+// Read an expression in brackets
+func (r *ExpressionReader) readExpression() (float64, error) {
+	if err := r.expect('['); err != nil {
+		return 0, err
+	}
+	value, err := r.readBinary(0)
+	if err != nil {
+		return 0, err
+	}
+	return value, r.expect(']')
+}
+
+// Read the operations of a precedence level and the higher ones
+func (r *ExpressionReader) readBinary(level int) (float64, error) {
+	if level == len(binaryOperators) {
+		return r.readRealValue()
+	}
+
+	left, err := r.readBinary(level + 1)
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		operator := r.readOperator(level)
+		if operator == "" {
+			return left, nil
+		}
+		right, err := r.readBinary(level + 1)
+		if err != nil {
+			return 0, err
+		}
+		if left, err = applyOperator(operator, left, right); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Read an operator of a precedence level, or return an empty string
+func (r *ExpressionReader) readOperator(level int) string {
+	if r.atEnd() {
+		return ""
+	}
+	rest := strings.ToUpper(r.text[r.position:])
+	for _, operator := range binaryOperators[level] {
+		// The power is not a multiplication
+		if operator == "*" && strings.HasPrefix(rest, "**") {
+			continue
+		}
+		if strings.HasPrefix(rest, operator) {
+			r.position += len(operator)
+			return operator
+		}
+	}
+	return ""
+}
+
+// Apply a binary operator, the comparisons and logic operators give 1 or 0
+func applyOperator(operator string, left float64, right float64) (float64, error) {
+	boolean := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	switch operator {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		if right == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return left / right, nil
+	case "MOD":
+		if right == 0 {
+			return 0, errors.New("modulo by zero")
+		}
+		// The result has the sign of the divisor, as in RS274NGC
+		result := math.Mod(left, right)
+		if result != 0 && (result < 0) != (right < 0) {
+			result += right
+		}
+		return result, nil
+	case "**":
+		return math.Pow(left, right), nil
+	case "EQ":
+		return boolean(left == right), nil
+	case "NE":
+		return boolean(left != right), nil
+	case "GT":
+		return boolean(left > right), nil
+	case "GE":
+		return boolean(left >= right), nil
+	case "LT":
+		return boolean(left < right), nil
+	case "LE":
+		return boolean(left <= right), nil
+	case "AND":
+		return boolean(left != 0 && right != 0), nil
+	case "OR":
+		return boolean(left != 0 || right != 0), nil
+	case "XOR":
+		return boolean((left != 0) != (right != 0)), nil
+	}
+	return 0, fmt.Errorf("unknown operator: %s", operator)
+}
+
+// Read a function of an expression in brackets, such as SIN[30]
+func (r *ExpressionReader) readFunction() (float64, error) {
+	start := r.position
+	for r.position < len(r.text) && isLetter(r.text[r.position]) {
+		r.position++
+	}
+	name := strings.ToUpper(r.text[start:r.position])
+
+	if name == "EXISTS" {
+		if err := r.expect('['); err != nil {
+			return 0, err
+		}
+		if err := r.expect('#'); err != nil {
+			return 0, err
+		}
+		reference, err := r.readReference()
+		if err != nil {
+			return 0, err
+		}
+		if reference.name == "" {
+			return 0, errors.New("EXISTS needs a named parameter")
+		}
+		if err := r.expect(']'); err != nil {
+			return 0, err
+		}
+		if r.parameters.exists(reference.name) {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	value, err := r.readExpression()
+	if err != nil {
+		return 0, err
+	}
+
+	switch name {
+	case "ABS":
+		return math.Abs(value), nil
+	case "ACOS", "ASIN":
+		if value < -1 || value > 1 {
+			return 0, fmt.Errorf("%s argument %g is out of range", name, value)
+		}
+		if name == "ACOS" {
+			return math.Acos(value) * 180 / math.Pi, nil
+		}
+		return math.Asin(value) * 180 / math.Pi, nil
+	case "ATAN":
+		// ATAN[y]/[x] gives the angle of the point x, y
+		if err := r.expect('/'); err != nil {
+			return 0, err
+		}
+		x, err := r.readExpression()
+		if err != nil {
+			return 0, err
+		}
+		return math.Atan2(value, x) * 180 / math.Pi, nil
+	case "COS":
+		return math.Cos(value * math.Pi / 180), nil
+	case "EXP":
+		return math.Exp(value), nil
+	case "FIX":
+		return math.Floor(value), nil
+	case "FUP":
+		return math.Ceil(value), nil
+	case "ROUND":
+		return math.Round(value), nil
+	case "LN":
+		if value <= 0 {
+			return 0, fmt.Errorf("LN argument %g is not positive", value)
+		}
+		return math.Log(value), nil
+	case "SIN":
+		return math.Sin(value * math.Pi / 180), nil
+	case "SQRT":
+		if value < 0 {
+			return 0, fmt.Errorf("SQRT argument %g is negative", value)
+		}
+		return math.Sqrt(value), nil
+	case "TAN":
+		return math.Tan(value * math.Pi / 180), nil
+	}
+	return 0, fmt.Errorf("unknown function: %s", name)
+}
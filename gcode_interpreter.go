@@ -0,0 +1,742 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Returned by the endsub, return and M99 lines to leave the subroutine
+var errSubroutineReturn = errors.New("return outside of a subroutine")
+
+// Deepest nesting of subroutine calls, to stop runaway recursions
+const maxCallDepth = 64
+
+// Most iterations of a loop, to stop the loops that never end
+const maxLoopIterations = 100000
+
+// Evaluate the parameters, expressions and O-word control flow of a program
+// before its lines reach the parser. The whole program is interpreted before
+// it runs, so the parameters the machine sets while running, such as the
+// probe results, cannot change the conditions and loops.
+type GCodeInterpreter struct {
+	parser      *GCodeParser
+	parameters  *GCodeParameters
+	subroutines map[string]*Subroutine
+	commands    []GCodeCommand
+
+	// Directories searched for the subroutines not defined in the program
+	searchPath []string
+
+	// File and lines being executed, the program or a subroutine
+	file  string
+	lines []string
+
+	// Remaining iterations of the repeat loops, by the index of their repeat line
+	repeats map[int]int
+
+	// Iterations of the loops running, by the index of their first line
+	iterations map[int]int
+
+	// Number of nested subroutine calls
+	depth int
+}
+
+// O-word line such as O100 if [#1 GT 0]
+type OWord struct {
+	label   string
+	keyword string
+	rest    string
+}
+
+// Create a new G-code interpreter
+func newGCodeInterpreter(parser *GCodeParser) *GCodeInterpreter {
+	return &GCodeInterpreter{parser: parser, parameters: newGCodeParameters()}
+}
+
+// Set the directories searched for the subroutines, in order
+func (g *GCodeInterpreter) setSearchPath(searchPath []string) {
+	g.searchPath = searchPath
+}
+
+// Get the parameters of the program
+func (g *GCodeInterpreter) getParameters() *GCodeParameters {
+	return g.parameters
+}
+
+// Use the parameters of a machine, such as its probe results
+func (g *GCodeInterpreter) setParameters(parameters *GCodeParameters) {
+	g.parameters = parameters
+}
+
+// Parse an O-word line, such as O100 call or O<probe> sub, after its block
+// delete slash and N number
+func parseOWord(line string) (OWord, bool) {
+	line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "/"))
+	if len(line) > 1 && (line[0] == 'N' || line[0] == 'n') {
+		line = strings.TrimLeft(line[1:], "0123456789 ")
+	}
+	if len(line) < 2 || (line[0] != 'O' && line[0] != 'o') {
+		return OWord{}, false
+	}
+
+	var word OWord
+	rest := line[1:]
+	if rest[0] == '<' {
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			return OWord{}, false
+		}
+		word.label = strings.ToLower(strings.Join(strings.Fields(rest[1:end]), ""))
+		rest = rest[end+1:]
+	} else {
+		end := 0
+		for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			return OWord{}, false
+		}
+		number, _ := strconv.Atoi(rest[:end])
+		word.label = strconv.Itoa(number)
+		rest = rest[end:]
+	}
+
+	rest = strings.TrimSpace(rest)
+	end := 0
+	for end < len(rest) && isLetter(rest[end]) {
+		end++
+	}
+	word.keyword = strings.ToLower(rest[:end])
+	word.rest = strings.TrimSpace(rest[end:])
+
+	return word, true
+}
+
+// Interpret the lines of a program into commands
+func (g *GCodeInterpreter) fromString(lines []string) ([]GCodeCommand, error) {
+	return g.run("", lines)
+}
+
+// Interpret the lines of a program read from a file. On an error, return
+// the commands interpreted before it.
+func (g *GCodeInterpreter) run(file string, lines []string) ([]GCodeCommand, error) {
+	g.file = file
+	g.lines = lines
+	g.commands = g.parser.startProgram()
+	g.repeats = make(map[int]int)
+	g.iterations = make(map[int]int)
+	g.subroutines = make(map[string]*Subroutine)
+	g.depth = 0
+
+	if err := g.findSubroutines(file, lines); err != nil {
+		return nil, err
+	}
+
+	if err := g.execute(0, len(lines)); err != nil && err != errSubroutineReturn {
+		return g.commands, err
+	}
+
+	return g.commands, nil
+}
+
+// Interpret the lines of a file into commands
+func (g *GCodeInterpreter) fromFile(filename string) ([]GCodeCommand, error) {
+	lines, err := readLines(filename)
+	if err != nil {
+		return nil, err
+	}
+	return g.run(filename, lines)
+}
+
+// Read the lines of a file
+func readLines(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+	return lines, scanner.Err()
+}
+
+// Check if a line has a word, such as M99
+func hasWord(line string, word string) bool {
+	code, _, err := splitComments(line)
+	if err != nil {
+		return false
+	}
+	words, err := tokenizeLine(code)
+	if err != nil {
+		return false
+	}
+	for _, w := range words {
+		if w.String() == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if a line ends the program
+func endsProgram(line string) bool {
+	return hasWord(line, "M2") || hasWord(line, "M30")
+}
+
+// Find the subroutines defined in the lines of a file. Numbered programs
+// called by M98 start with their O number and end with M99.
+func (g *GCodeInterpreter) findSubroutines(file string, lines []string) error {
+	for i := 0; i < len(lines); i++ {
+		word, ok := parseOWord(lines[i])
+		if !ok || (word.keyword != "sub" && word.keyword != "") {
+			continue
+		}
+
+		end := -1
+		if word.keyword == "sub" {
+			end = findOWord(lines, i+1, 1, word.label, "endsub")
+			if end < 0 {
+				return newGCodeError(file, i+1, -1, fmt.Sprintf("subroutine %s has no endsub", word.label))
+			}
+		} else {
+			// A program number followed by the end of the program is the main program
+			for j := i + 1; j < len(lines) && end < 0; j++ {
+				if isProgramNumber(lines[j]) || endsProgram(lines[j]) {
+					break
+				}
+				if hasWord(lines[j], "M99") {
+					end = j
+				}
+			}
+			if end < 0 {
+				continue
+			}
+		}
+
+		if _, ok := g.subroutines[word.label]; ok {
+			return newGCodeError(file, i+1, -1, fmt.Sprintf("subroutine %s is already defined", word.label))
+		}
+		g.subroutines[word.label] = newSubroutine(word.label, file, lines, i, end)
+		i = end
+	}
+
+	return nil
+}
+
+// Check if a line is a program number such as O1000
+func isProgramNumber(line string) bool {
+	word, ok := parseOWord(line)
+	return ok && word.keyword == ""
+}
+
+// Find the next line in a direction with an O-word of the label and one of the keywords
+func (g *GCodeInterpreter) find(from int, step int, label string, keywords ...string) int {
+	return findOWord(g.lines, from, step, label, keywords...)
+}
+
+// Find the next line of lines in a direction with an O-word of the label and one of the keywords
+func findOWord(lines []string, from int, step int, label string, keywords ...string) int {
+	for i := from; i >= 0 && i < len(lines); i += step {
+		word, ok := parseOWord(lines[i])
+		if !ok || word.label != label {
+			continue
+		}
+		for _, keyword := range keywords {
+			if word.keyword == keyword {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Get the keyword of the O-word on a line
+func (g *GCodeInterpreter) keywordAt(index int) string {
+	word, _ := parseOWord(g.lines[index])
+	return word.keyword
+}
+
+// Wrap an error with the line it happened on, unless it already has one
+func (g *GCodeInterpreter) lineError(index int, err error) error {
+	var gcodeError *GCodeError
+	if err == errSubroutineReturn || errors.As(err, &gcodeError) {
+		return err
+	}
+	return newGCodeError(g.file, index+1, g.parser.lineNumber, err.Error())
+}
+
+// Execute the lines from start up to end
+func (g *GCodeInterpreter) execute(start int, end int) error {
+	pc := start
+	for pc < end {
+		g.parser.setLocation(g.file, pc+1)
+		line, skip, err := g.parser.prepareLine(g.lines[pc])
+		if err != nil {
+			return g.lineError(pc, err)
+		}
+		if skip {
+			pc++
+			continue
+		}
+
+		word, ok := parseOWord(line)
+		if !ok {
+			if err := g.executeLine(pc, line); err != nil {
+				return g.lineError(pc, err)
+			}
+			pc++
+			continue
+		}
+
+		next, err := g.executeOWord(pc, word)
+		if err != nil {
+			return g.lineError(pc, err)
+		}
+		pc = next
+	}
+
+	return nil
+}
+
+// Execute an O-word and return the index of the next line to execute
+func (g *GCodeInterpreter) executeOWord(pc int, word OWord) (int, error) {
+	label := word.label
+
+	switch word.keyword {
+	case "", "sub":
+		// Definitions are skipped, the subroutine runs when called
+		if subroutine, ok := g.subroutines[label]; ok && subroutine.file == g.file && subroutine.start == pc {
+			return subroutine.end + 1, nil
+		}
+		// Program number of the main program
+		return pc + 1, nil
+	case "endsub", "return":
+		if word.rest != "" {
+			value, err := newExpressionReader(word.rest, g.parameters).readExpression()
+			if err != nil {
+				return 0, err
+			}
+			g.parameters.set(ParameterReference{name: "_value"}, value)
+		}
+		return 0, errSubroutineReturn
+	case "call":
+		return pc + 1, g.call(pc, label, word.rest)
+	case "if", "elseif":
+		if word.keyword == "elseif" {
+			// A branch was taken, skip the others
+			return g.findOrFail(pc, 1, label, "endif")
+		}
+		return g.branch(pc, word)
+	case "else":
+		return g.findOrFail(pc, 1, label, "endif")
+	case "endif", "do":
+		return pc + 1, nil
+	case "while":
+		return g.loop(pc, word)
+	case "endwhile":
+		return g.findOrFail(pc, -1, label, "while")
+	case "repeat":
+		count, err := newExpressionReader(word.rest, g.parameters).readExpression()
+		if err != nil {
+			return 0, err
+		}
+		if int(count) < 1 {
+			end, err := g.findOrFail(pc, 1, label, "endrepeat")
+			return end + 1, err
+		}
+		g.repeats[pc] = int(count)
+		return pc + 1, nil
+	case "endrepeat":
+		repeat, err := g.findOrFail(pc, -1, label, "repeat")
+		if err != nil {
+			return 0, err
+		}
+		g.repeats[repeat]--
+		if g.repeats[repeat] > 0 {
+			return repeat + 1, g.iterate(repeat, label)
+		}
+		delete(g.iterations, repeat)
+		return pc + 1, nil
+	case "break", "continue":
+		return g.jumpInLoop(pc, label, word.keyword == "break")
+	}
+
+	return 0, fmt.Errorf("unknown O-word: %s", word.keyword)
+}
+
+// Find a line with an O-word of the label, or fail if there is none
+func (g *GCodeInterpreter) findOrFail(pc int, step int, label string, keywords ...string) (int, error) {
+	index := g.find(pc+step, step, label, keywords...)
+	if index < 0 {
+		return 0, fmt.Errorf("O%s %s without %s", label, g.keywordAt(pc), strings.Join(keywords, " or "))
+	}
+	return index, nil
+}
+
+// Evaluate the condition of an O-word
+func (g *GCodeInterpreter) condition(word OWord) (bool, error) {
+	value, err := newExpressionReader(word.rest, g.parameters).readExpression()
+	return value != 0, err
+}
+
+// Go to the first branch of an if whose condition is true, or past the endif
+func (g *GCodeInterpreter) branch(pc int, word OWord) (int, error) {
+	for {
+		taken, err := g.condition(word)
+		if err != nil {
+			return 0, g.lineError(pc, err)
+		}
+		if taken {
+			return pc + 1, nil
+		}
+
+		pc, err = g.findOrFail(pc, 1, word.label, "elseif", "else", "endif")
+		if err != nil {
+			return 0, err
+		}
+		word, _ = parseOWord(g.lines[pc])
+		if word.keyword != "elseif" {
+			return pc + 1, nil
+		}
+	}
+}
+
+// Check if a while line ends a do loop rather than starting a while loop
+func (g *GCodeInterpreter) endsDoLoop(pc int, label string) bool {
+	previous := g.find(pc-1, -1, label, "do", "while", "endwhile")
+	return previous >= 0 && g.keywordAt(previous) == "do"
+}
+
+// Evaluate the condition of a while loop, or of the end of a do loop
+func (g *GCodeInterpreter) loop(pc int, word OWord) (int, error) {
+	again, err := g.condition(word)
+	if err != nil {
+		return 0, err
+	}
+
+	if g.endsDoLoop(pc, word.label) {
+		do, err := g.findOrFail(pc, -1, word.label, "do")
+		if err != nil {
+			return 0, err
+		}
+		if again {
+			return do + 1, g.iterate(do, word.label)
+		}
+		delete(g.iterations, do)
+		return pc + 1, nil
+	}
+
+	if again {
+		return pc + 1, g.iterate(pc, word.label)
+	}
+	delete(g.iterations, pc)
+	end, err := g.findOrFail(pc, 1, word.label, "endwhile")
+	return end + 1, err
+}
+
+// Count an iteration of the loop starting at a line, failing once there are too many
+func (g *GCodeInterpreter) iterate(start int, label string) error {
+	g.iterations[start]++
+	if g.iterations[start] > maxLoopIterations {
+		return fmt.Errorf("O%s loop is still running after %d iterations", label, maxLoopIterations)
+	}
+	return nil
+}
+
+// Leave the loop of the label with a break, or go to its next iteration with a continue
+func (g *GCodeInterpreter) jumpInLoop(pc int, label string, leave bool) (int, error) {
+	start, err := g.findOrFail(pc, -1, label, "while", "do", "repeat")
+	if err != nil {
+		return 0, err
+	}
+
+	// Line checking the condition or counting the iterations of the loop
+	var end int
+	switch g.keywordAt(start) {
+	case "while":
+		end, err = g.findOrFail(pc, 1, label, "endwhile")
+	case "do":
+		end, err = g.findOrFail(pc, 1, label, "while")
+	case "repeat":
+		end, err = g.findOrFail(pc, 1, label, "endrepeat")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if leave {
+		delete(g.iterations, start)
+		return end + 1, nil
+	}
+	if g.keywordAt(start) == "while" {
+		return start, nil
+	}
+	return end, nil
+}
+
+// Call a subroutine with the arguments in brackets following the call
+func (g *GCodeInterpreter) call(pc int, label string, rest string) error {
+	var arguments []float64
+	reader := newExpressionReader(rest, g.parameters)
+	for !reader.atEnd() {
+		argument, err := reader.readExpression()
+		if err != nil {
+			return err
+		}
+		arguments = append(arguments, argument)
+	}
+
+	subroutine, err := g.getSubroutine(label)
+	if err != nil {
+		return err
+	}
+	return g.callSubroutine(pc, "O<"+label+"> call", subroutine, arguments)
+}
+
+// Call a numbered program a number of times (M98 P L)
+func (g *GCodeInterpreter) callProgram(pc int, number float64, count float64) error {
+	label := strconv.Itoa(int(number))
+	subroutine, err := g.getSubroutine(label)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < int(count); i++ {
+		if err := g.callSubroutine(pc, "M98 P"+label, subroutine, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get a subroutine of the program, or search for its file
+func (g *GCodeInterpreter) getSubroutine(label string) (*Subroutine, error) {
+	if subroutine, ok := g.subroutines[label]; ok {
+		return subroutine, nil
+	}
+
+	// LinuxCNC names the files after the subroutine, Fanuc after the program number
+	candidates := []string{label + ".ngc"}
+	number, err := strconv.Atoi(label)
+	numbered := err == nil
+	if numbered {
+		candidates = append(candidates, fmt.Sprintf("O%04d.nc", number), fmt.Sprintf("O%04d.ngc", number))
+	}
+
+	for _, directory := range g.searchPath {
+		for _, candidate := range candidates {
+			filename := filepath.Join(directory, candidate)
+			lines, err := readLines(filename)
+			if err != nil {
+				continue
+			}
+			if err := g.findSubroutines(filename, lines); err != nil {
+				return nil, err
+			}
+			if subroutine, ok := g.subroutines[label]; ok {
+				return subroutine, nil
+			}
+			if !numbered {
+				return nil, fmt.Errorf("%s does not define subroutine %s", filename, label)
+			}
+
+			// Numbered program files can hold the program alone, up to M99 or the end of the file
+			end := len(lines) - 1
+			for i, line := range lines {
+				if hasWord(line, "M99") {
+					end = i
+					break
+				}
+			}
+			g.subroutines[label] = newSubroutine(label, filename, lines, -1, end)
+			return g.subroutines[label], nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown subroutine: %s", label)
+}
+
+// Execute a subroutine with its arguments. Errors in the subroutine get the call in their call stack.
+func (g *GCodeInterpreter) callSubroutine(pc int, call string, subroutine *Subroutine, arguments []float64) error {
+	if g.depth >= maxCallDepth {
+		return g.lineError(pc, fmt.Errorf("subroutine calls are nested deeper than %d", maxCallDepth))
+	}
+
+	saved, err := g.parameters.enterSubroutine(arguments)
+	if err != nil {
+		return err
+	}
+
+	file, lines, repeats, iterations := g.file, g.lines, g.repeats, g.iterations
+	g.file, g.lines, g.repeats, g.iterations = subroutine.file, subroutine.lines, make(map[int]int), make(map[int]int)
+	g.depth++
+
+	err = g.execute(subroutine.start+1, subroutine.end+1)
+
+	g.depth--
+	g.file, g.lines, g.repeats, g.iterations = file, lines, repeats, iterations
+	g.parameters.exitSubroutine(saved)
+
+	if err == errSubroutineReturn {
+		return nil
+	}
+
+	var gcodeError *GCodeError
+	if errors.As(err, &gcodeError) {
+		gcodeError.addCall(call + " at " + lineLocation(g.file, pc+1))
+	}
+	return err
+}
+
+// Evaluate the words of a line and pass it to the parser. The parameters set
+// on the line only change once the whole line is read.
+func (g *GCodeInterpreter) executeLine(pc int, line string) error {
+	// Empty lines are passed through as is
+	if len(line) == 0 || line[0] == ';' || line[0] == '%' {
+		g.commands = append(g.commands, g.parser.parseBlock(line)...)
+		return nil
+	}
+
+	type assignment struct {
+		reference ParameterReference
+		value     float64
+	}
+	var assignments []assignment
+	var messages []string
+	var words []string
+	values := make(map[byte]float64)
+
+	reader := newExpressionReader(line, g.parameters)
+	for !reader.atEnd() {
+		c := reader.peek()
+		switch {
+		case c == '(':
+			// Comment up to the closing parenthesis
+			end := strings.IndexByte(line[reader.position:], ')')
+			if end < 0 {
+				return errors.New("unterminated comment")
+			}
+			comment := line[reader.position+1 : reader.position+end]
+			reader.position += end + 1
+
+			// Debug and print messages show the values of their parameters
+			if messageType, text, ok := parseOperatorMessage(comment); ok {
+				if messageType != MessageOperator {
+					var err error
+					if text, err = g.formatMessage(text); err != nil {
+						return err
+					}
+				}
+				messages = append(messages, "("+messageType.String()+","+text+")")
+			}
+		case c == ';':
+			reader.position = len(line)
+		case c == '#':
+			reader.position++
+			reference, err := reader.readReference()
+			if err != nil {
+				return err
+			}
+			if err := reader.expect('='); err != nil {
+				return err
+			}
+			value, err := reader.readRealValue()
+			if err != nil {
+				return err
+			}
+			assignments = append(assignments, assignment{reference, value})
+		case isLetter(c):
+			reader.position++
+			value, err := reader.readRealValue()
+			if err != nil {
+				return err
+			}
+			words = append(words, string(c)+strconv.FormatFloat(value, 'f', -1, 64))
+			values[c] = value
+		default:
+			return fmt.Errorf("unexpected character at: %s", reader.rest())
+		}
+	}
+
+	for _, a := range assignments {
+		if err := g.parameters.set(a.reference, a.value); err != nil {
+			return err
+		}
+	}
+
+	if len(messages) > 0 {
+		g.commands = append(g.commands, g.parser.parseBlock(strings.Join(messages, " "))...)
+	} else if len(words) == 0 && line[0] == '(' {
+		g.commands = append(g.commands, g.parser.parseBlock(line)...)
+	}
+
+	// Numbered program calls and returns are run by the interpreter, once the
+	// other words of the line are parsed
+	calls, returns := false, false
+	for _, word := range words {
+		calls = calls || word == "M98"
+		returns = returns || word == "M99"
+	}
+	var block []string
+	for _, word := range words {
+		if word == "M98" || word == "M99" || (calls && (word[0] == 'P' || word[0] == 'L')) {
+			continue
+		}
+		block = append(block, word)
+	}
+
+	if len(block) > 0 {
+		g.commands = append(g.commands, g.parser.parseBlock(strings.Join(block, " "))...)
+	}
+
+	if calls {
+		number, ok := values['P']
+		if !ok {
+			return errors.New("M98 without a P word")
+		}
+		count, ok := values['L']
+		if !ok {
+			count = 1
+		}
+		if err := g.callProgram(pc, number, count); err != nil {
+			return err
+		}
+	}
+	if returns {
+		return errSubroutineReturn
+	}
+	return nil
+}
+
+// Replace the parameter references of a message, such as #1 or #<depth>, by their values
+func (g *GCodeInterpreter) formatMessage(text string) (string, error) {
+	var message strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '#' {
+			message.WriteByte(text[i])
+			continue
+		}
+
+		reader := newExpressionReader(text[i+1:], g.parameters)
+		reference, err := reader.readReference()
+		if err != nil {
+			return "", err
+		}
+		value, err := g.parameters.get(reference)
+		if err != nil {
+			return "", err
+		}
+		message.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+		i += reader.position
+	}
+	return message.String(), nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Interpret lines and get the X of their G1 moves
+func interpretMoves(t *testing.T, lines []string) []float64 {
+	t.Helper()
+	commands, err := newGCodeInterpreter(newGCodeParser()).fromString(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var moves []float64
+	for _, command := range commands {
+		if command.command == "G1" {
+			moves = append(moves, command.params["X"])
+		}
+	}
+	return moves
+}
+
+// Check the X of the moves of the lines
+func checkMoves(t *testing.T, lines []string, expected []float64) {
+	t.Helper()
+	if moves := interpretMoves(t, lines); fmt.Sprint(moves) != fmt.Sprint(expected) {
+		t.Errorf("got moves %v, expected %v", moves, expected)
+	}
+}
+
+func TestInterpreterWhileLoop(t *testing.T) {
+	checkMoves(t, []string{
+		"#1 = 0",
+		"o1 while [#1 LT 3]",
+		"G1 X#1 F100",
+		"#1 = [#1 + 1]",
+		"o1 endwhile",
+	}, []float64{0, 1, 2})
+}
+
+func TestInterpreterConditions(t *testing.T) {
+	checkMoves(t, []string{
+		"#<count> = 2",
+		"o1 if [#<count> EQ 1]",
+		"G1 X1 F100",
+		"o1 elseif [#<count> EQ 2]",
+		"G1 X2 F100",
+		"o1 else",
+		"G1 X3 F100",
+		"o1 endif",
+	}, []float64{2})
+}
+
+func TestInterpreterExpressions(t *testing.T) {
+	// Powers come before products, products before sums, and the modulo has
+	// the sign of the divisor
+	checkMoves(t, []string{
+		"G1 X[1 + 2 * 3 ** 2] F100",
+		"G1 X[-7 MOD 3]",
+		"G1 X[ABS[-2] + SQRT[16]]",
+		"G1 X[ATAN[1]/[1]]",
+		"G1 X[2 GT 1 AND 3 LE 3]",
+	}, []float64{19, 2, 6, 45, 1})
+
+	if _, err := newGCodeInterpreter(newGCodeParser()).fromString([]string{"G1 X[1/0] F100"}); err == nil {
+		t.Errorf("got no error for a division by zero")
+	}
+}
+
+func TestInterpreterDoLoop(t *testing.T) {
+	// The body of a do loop runs once before the condition, continue skips
+	// to the condition and break leaves the loop
+	checkMoves(t, []string{
+		"#1 = 0",
+		"o1 do",
+		"#1 = [#1 + 1]",
+		"o2 if [#1 EQ 2]",
+		"o1 continue",
+		"o2 endif",
+		"o2 if [#1 EQ 4]",
+		"o1 break",
+		"o2 endif",
+		"G1 X#1 F100",
+		"o1 while [#1 LT 10]",
+		"G1 X100",
+	}, []float64{1, 3, 100})
+}
+
+func TestInterpreterNestedRepeats(t *testing.T) {
+	checkMoves(t, []string{
+		"#1 = 0",
+		"o1 repeat [3]",
+		"o2 repeat [2]",
+		"#1 = [#1 + 1]",
+		"G1 X#1 F100",
+		"o2 endrepeat",
+		"o1 endrepeat",
+	}, []float64{1, 2, 3, 4, 5, 6})
+}
+
+func TestInterpreterEndlessLoops(t *testing.T) {
+	// The error is on the line checking the condition or counting the iterations
+	for _, test := range []struct {
+		name  string
+		lines []string
+		line  int
+	}{
+		{"while", []string{"G0 X0", "o1 while [1]", "o1 endwhile"}, 2},
+		{"do", []string{"G0 X0", "o1 do", "o1 while [1]"}, 3},
+		{"repeat", []string{"G0 X0", "o1 repeat [1000000]", "o1 endrepeat"}, 3},
+	} {
+		_, err := newGCodeInterpreter(newGCodeParser()).fromString(test.lines)
+		var gcodeError *GCodeError
+		if !errors.As(err, &gcodeError) {
+			t.Errorf("%s: got %v, expected an error on a line", test.name, err)
+			continue
+		}
+		if gcodeError.getLine() != test.line {
+			t.Errorf("%s: got the error %v on line %d, expected line %d", test.name, err, gcodeError.getLine(), test.line)
+		}
+	}
+}
+
+func TestInterpreterLoopIterationsAreCountedPerRun(t *testing.T) {
+	// Each run of the inner loop is under the limit, all of them are not
+	checkMoves(t, []string{
+		"o1 repeat [2]",
+		fmt.Sprintf("o2 repeat [%d]", maxLoopIterations),
+		"o2 endrepeat",
+		"o1 endrepeat",
+		"G1 X1 F100",
+	}, []float64{1})
+}
+
+func TestInterpreterProgramCall(t *testing.T) {
+	// The move of the M98 line is made before the call
+	checkMoves(t, []string{
+		"G1 X10 F100 M98 P100 L2",
+		"G1 X20",
+		"M30",
+		"O100",
+		"G1 X5",
+		"M99",
+	}, []float64{10, 5, 5, 20})
+}
+
+func TestInterpreterProgramCallWithoutNumber(t *testing.T) {
+	_, err := newGCodeInterpreter(newGCodeParser()).fromString([]string{"G0 X0", "M98 L2"})
+	var gcodeError *GCodeError
+	if !errors.As(err, &gcodeError) || gcodeError.getLine() != 2 {
+		t.Errorf("got %v, expected an error on line 2", err)
+	}
+}
+
+func TestInterpreterRecursionLimit(t *testing.T) {
+	_, err := newGCodeInterpreter(newGCodeParser()).fromString([]string{
+		"O<again> sub",
+		"O<again> call",
+		"O<again> endsub",
+		"O<again> call",
+	})
+	var gcodeError *GCodeError
+	if !errors.As(err, &gcodeError) || gcodeError.getLine() != 2 {
+		t.Errorf("got %v, expected an error on the call of the subroutine", err)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Word of a G-code line, a letter and its value, such as X-.5
+type GCodeWord struct {
+	letter byte
+	value  float64
+}
+
+// Return the word with its value in its shortest form, such as G1 for G01
+func (w GCodeWord) String() string {
+	return string(w.letter) + strconv.FormatFloat(w.value, 'f', -1, 64)
+}
+
+// Check if a character is a space. Spaces are ignored outside of the comments.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// Scan a number without sign starting at a position. The spaces between its
+// digits are part of the number. Return its digits and the position after it.
+func scanNumber(text string, start int) (string, int) {
+	var digits strings.Builder
+	end := start
+	for i := start; i < len(text); i++ {
+		if text[i] == '.' || (text[i] >= '0' && text[i] <= '9') {
+			digits.WriteByte(text[i])
+			end = i + 1
+		} else if !isSpace(text[i]) {
+			break
+		}
+	}
+	return digits.String(), end
+}
+
+// Split the code of a line, without its comments, into words. The letters
+// are not case sensitive, the words do not need spaces between them and the
+// values can be signed, such as g1x10y-.5.
+func tokenizeLine(code string) ([]GCodeWord, error) {
+	var words []GCodeWord
+	i := 0
+	for {
+		for i < len(code) && isSpace(code[i]) {
+			i++
+		}
+		if i >= len(code) {
+			return words, nil
+		}
+
+		letter := code[i]
+		if !isLetter(letter) {
+			return nil, fmt.Errorf("invalid word at: %s", code[i:])
+		}
+		if letter >= 'a' && letter <= 'z' {
+			letter -= 'a' - 'A'
+		}
+		i++
+
+		for i < len(code) && isSpace(code[i]) {
+			i++
+		}
+		sign := 1.0
+		if i < len(code) && (code[i] == '-' || code[i] == '+') {
+			if code[i] == '-' {
+				sign = -1
+			}
+			i++
+		}
+
+		digits, end := scanNumber(code, i)
+		if digits == "" {
+			return nil, fmt.Errorf("missing value for: %c", letter)
+		}
+		value, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %c%s", letter, code[i:end])
+		}
+
+		words = append(words, GCodeWord{letter: letter, value: sign * value})
+		i = end
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTokenizeLine(t *testing.T) {
+	for code, expected := range map[string]string{
+		"G1 X10 Y-.5":    "[G1 X10 Y-0.5]",
+		"g1x10y-.5":      "[G1 X10 Y-0.5]",
+		"G01 X+1.25":     "[G1 X1.25]",
+		"G 1 X 1 0 . 5 ": "[G1 X10.5]",
+		"":               "[]",
+	} {
+		words, err := tokenizeLine(code)
+		if err != nil {
+			t.Errorf("%q: %v", code, err)
+			continue
+		}
+		if fmt.Sprint(words) != expected {
+			t.Errorf("%q: got %v, expected %s", code, words, expected)
+		}
+	}
+}
+
+func TestTokenizeLineErrors(t *testing.T) {
+	for _, code := range []string{"G", "X--1", "1G", "G1 X1.2.3", "G1 #1"} {
+		if words, err := tokenizeLine(code); err == nil {
+			t.Errorf("%q: got %v, expected an error", code, words)
+		}
+	}
+}
+
+func TestJogCommandWords(t *testing.T) {
+	jog, err := parseJogCommand("$J=g91x10y-2.5f600")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVector(t, "jog target", jog.getTarget(Vector3d{X: 1, Y: 1}, Vector3d{}), Vector3d{X: 11, Y: -1.5})
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+)
+
+// Largest difference between the radius to the start and to the end of an
+// arc, in millimeters or in inches, and relative to the radius
+const arcRadiusTolerance = 0.002
+const arcRadiusToleranceInch = 0.0002
+const arcRadiusRelativeTolerance = 0.001
+
+// Positions of each planned movement checked against the soft limits
+const softLimitSamples = 8
+
+// Modal groups a program must set before its first move, with their codes
+var lintModalGroups = []struct {
+	name  string
+	codes []string
+}{
+	{"units", []string{"G20", "G21"}},
+	{"plane", []string{"G17", "G18", "G19"}},
+	{"distance mode", []string{"G90", "G91"}},
+}
+
+// Check a program before running it: parse and plan it without a machine,
+// and report what would stop or spoil the job
+type GCodeLinter struct {
+	dialect       *GCodeDialect
+	configuration *MachineConfiguration
+
+	// Number of tools of the tool changer, the tools are not checked when 0
+	toolCount int
+
+	findings []LintFinding
+}
+
+// Create a new linter for the programs of a dialect and a machine
+func newGCodeLinter(dialect *GCodeDialect, configuration *MachineConfiguration) *GCodeLinter {
+	return &GCodeLinter{dialect: dialect, configuration: configuration}
+}
+
+// Set the number of tools of the tool changer
+func (l *GCodeLinter) setToolCount(toolCount int) {
+	l.toolCount = toolCount
+}
+
+// Add a finding on the line of a command
+func (l *GCodeLinter) add(command GCodeCommand, severity string, check string, format string, args ...interface{}) {
+	l.findings = append(l.findings, LintFinding{
+		File:       command.file,
+		Line:       command.line,
+		LineNumber: command.lineNumber,
+		Severity:   severity,
+		Check:      check,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// Lint a program file. The subroutines are searched in its directory.
+func (l *GCodeLinter) lint(filename string) ([]LintFinding, error) {
+	l.findings = nil
+
+	parser := newGCodeParser()
+	parser.setDialect(l.dialect)
+	parser.setReporter(func(diagnostic GCodeDiagnostic) {
+		check := "syntax"
+		if diagnostic.unsupported {
+			check = "unsupported-code"
+		}
+		severity := LintError
+		if diagnostic.warning {
+			severity = LintWarning
+		}
+		l.add(GCodeCommand{file: diagnostic.file, line: diagnostic.line, lineNumber: diagnostic.lineNumber}, severity, check, "%s", diagnostic.getMessage())
+	})
+
+	interpreter := newGCodeInterpreter(parser)
+	interpreter.setSearchPath([]string{filepath.Dir(filename)})
+	commands, err := interpreter.fromFile(filename)
+
+	// The program cannot be interpreted past an error, the commands before it
+	// are still checked
+	var gcodeError *GCodeError
+	if errors.As(err, &gcodeError) {
+		l.findings = append(l.findings, LintFinding{
+			File:       gcodeError.file,
+			Line:       gcodeError.line,
+			LineNumber: gcodeError.lineNumber,
+			Severity:   LintError,
+			Check:      "syntax",
+			Message:    gcodeError.message,
+		})
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.checkCommands(commands)
+
+	// Order the findings by line, the files of the subroutines in the order
+	// they are first reported
+	files := make(map[string]int)
+	for _, finding := range l.findings {
+		if _, ok := files[finding.File]; !ok {
+			files[finding.File] = len(files)
+		}
+	}
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.File != b.File {
+			return files[a.File] < files[b.File]
+		}
+		return a.Line < b.Line
+	})
+	return l.findings, nil
+}
+
+// Check the commands of a program, planning them one at a time
+func (l *GCodeLinter) checkCommands(commands []GCodeCommand) {
+	planner := newMotionPlanner(l.configuration)
+	modalGroupSet := make(map[string]bool)
+	modalGroupReported := make(map[string]bool)
+	plane := ZAxis
+	tolerance := arcRadiusTolerance
+	spindleOn := false
+	spindleReported := false
+	toolCenterPoint := newToolCenterPoint(false, 0)
+
+	for _, command := range commands {
+		switch command.command {
+		case "G17":
+			plane = ZAxis
+		case "G18":
+			plane = YAxis
+		case "G19":
+			plane = XAxis
+		case "G20":
+			tolerance = arcRadiusToleranceInch
+		case "G21":
+			tolerance = arcRadiusTolerance
+		case "M3", "M4":
+			spindleOn = true
+			spindleReported = false
+		case "M5":
+			spindleOn = false
+		case "M6":
+			l.checkTool(command)
+		}
+
+		// The startup code of the dialect does not count as setting the modal
+		// groups, the program must not rely on it
+		for _, group := range lintModalGroups {
+			for _, code := range group.codes {
+				if command.command == code && command.line > 0 {
+					modalGroupSet[group.name] = true
+				}
+			}
+		}
+
+		moves := motionCommands[command.command] && command.command != "G80"
+		if moves {
+			for _, group := range lintModalGroups {
+				if !modalGroupSet[group.name] && !modalGroupReported[group.name] {
+					modalGroupReported[group.name] = true
+					l.add(command, LintWarning, "modal-state", "move before the %s is set (%v)", group.name, group.codes)
+				}
+			}
+		}
+
+		cuts := command.command == "G1" || command.command == "G2" || command.command == "G3" ||
+			isCannedCycle(command.command) || command.command == "G33" || command.command == "G33.1" || command.command == "G76"
+		_, extrudes := command.params["E"]
+		if cuts && !extrudes && !spindleOn && !spindleReported {
+			spindleReported = true
+			l.add(command, LintWarning, "spindle-off", "%s cuts with the spindle off, M3 or M4 is missing", command.command)
+		}
+
+		if _, ok := probeTypeFromCommand(command.command); ok || (cuts && command.command != "G33" && command.command != "G33.1" && command.command != "G76") {
+			if command.params["F"] <= 0 {
+				l.add(command, LintError, "feed-rate", "%s feed move with F%g", command.command, command.params["F"])
+			}
+		}
+
+		start := planner.commandList.getPreviousPosition()
+		if command.command == "G2" || command.command == "G3" {
+			l.checkArc(command, start, plane, tolerance)
+		}
+
+		// Plan the command alone to check its movements
+		planned := len(planner.commandList.arr)
+		rejected := len(planner.getErrors())
+		planner.fromParsedGcode([]GCodeCommand{command})
+		for _, err := range planner.getErrors()[rejected:] {
+			l.add(command, LintError, "planning", "%v", err)
+		}
+		l.checkSoftLimits(command, planner.commandList.arr[planned:])
+		l.checkMotorReach(command, planner, planner.commandList.arr[planned:])
+		toolCenterPoint = l.checkJointLimits(command, planner, planner.commandList.arr[planned:], toolCenterPoint)
+	}
+}
+
+// Check that the tool of a tool change exists in the tool changer, T0 empties the spindle
+func (l *GCodeLinter) checkTool(command GCodeCommand) {
+	tool, ok := command.params["T"]
+	if !ok {
+		l.add(command, LintError, "tool-number", "tool change without a T word")
+	} else if tool != math.Trunc(tool) || tool < 0 {
+		l.add(command, LintError, "tool-number", "tool T%g is not a tool number", tool)
+	} else if l.toolCount > 0 && int(tool) > l.toolCount {
+		l.add(command, LintError, "tool-number", "tool T%g is not in the tool changer, T0 to T%d", tool, l.toolCount)
+	}
+}
+
+// Check that the start and the end of an arc are at the same distance of its
+// center, or that its radius reaches its end. The center and the radius are
+// only taken from the words on the line, the carried ones belong to the
+// previous arcs.
+func (l *GCodeLinter) checkArc(command GCodeCommand, start Vector3d, plane Axis, tolerance float64) {
+	end := Vector3d{X: command.params["X"], Y: command.params["Y"], Z: command.params["Z"]}
+
+	lineWord := func(letter string) float64 {
+		if command.isOnLine(letter) {
+			return command.params[letter]
+		}
+		return 0
+	}
+
+	if !command.isOnLine("I") && !command.isOnLine("J") && !command.isOnLine("K") {
+		if !command.isOnLine("R") {
+			l.add(command, LintError, "arc-radius", "%s arc without a center or a radius", command.command)
+			return
+		}
+		radius := command.params["R"]
+		if chord := end.subtract(start).project(plane).length(); chord > 2*math.Abs(radius)+tolerance {
+			l.add(command, LintError, "arc-radius", "%s arc radius R%g is too small for its %g long chord", command.command, radius, chord)
+		}
+		return
+	}
+
+	center := start.Add(Vector3d{X: lineWord("I"), Y: lineWord("J"), Z: lineWord("K")})
+	startRadius := start.subtract(center).project(plane).length()
+	endRadius := end.subtract(center).project(plane).length()
+	difference := math.Abs(endRadius - startRadius)
+	if difference > tolerance && difference > arcRadiusRelativeTolerance*startRadius {
+		l.add(command, LintError, "arc-radius", "%s arc radius to the end %g differs from the radius to the start %g", command.command, endRadius, startRadius)
+	}
+}
+
+// Check that the planned movements of a command stay within the soft limits
+func (l *GCodeLinter) checkSoftLimits(command GCodeCommand, planned []interface{}) {
+	if !l.configuration.soft_limits {
+		return
+	}
+
+	min := l.configuration.softLimitMin
+	max := l.configuration.softLimitMax
+	for _, item := range planned {
+		movement, ok := item.(Movement)
+		if !ok {
+			continue
+		}
+		for i := 0; i <= softLimitSamples; i++ {
+			position := movement.getPositionAt(float64(i) / softLimitSamples)
+			if !position.isFinite() {
+				l.add(command, LintError, "soft-limits", "%s moves to %v, which is not a position", command.command, position)
+				return
+			}
+			if position.X < min.X || position.Y < min.Y || position.Z < min.Z || position.X > max.X || position.Y > max.Y || position.Z > max.Z {
+				l.add(command, LintError, "soft-limits", "%s moves to %v, outside the soft limits", command.command, position)
+				return
+			}
+		}
+	}
+}
+
+// Check that the motors reach the planned movements of a command
+func (l *GCodeLinter) checkMotorReach(command GCodeCommand, planner *MotionPlanner, planned []interface{}) {
+	for _, item := range planned {
+		movement, ok := item.(Movement)
+		if !ok {
+			continue
+		}
+		if err := planner.checkMotorReach(movement); err != nil {
+			l.add(command, LintError, "motor-reach", "%s: %v", command.command, err)
+			return
+		}
+	}
+}
+
+// Check that the joints of a five axis machine stay within their travel along
+// the planned movements of a command, and return the tool center point
+// control active after them
+func (l *GCodeLinter) checkJointLimits(command GCodeCommand, planner *MotionPlanner, planned []interface{}, toolCenterPoint *ToolCenterPoint) *ToolCenterPoint {
+	reported := false
+	for _, item := range planned {
+		if next, ok := item.(*ToolCenterPoint); ok {
+			toolCenterPoint = next
+		}
+		movement, ok := item.(Movement)
+		if !ok || reported || !toolCenterPoint.isEnabled() || l.configuration.fiveAxisKinematics == nil {
+			continue
+		}
+		if err := planner.checkJointLimits(movement, toolCenterPoint); err != nil {
+			l.add(command, LintError, "joint-limits", "%s: %v", command.command, err)
+			reported = true
+		}
+	}
+	return toolCenterPoint
+}
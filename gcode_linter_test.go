@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Set the modal groups and start the spindle before the checked lines
+var lintHeader = []string{"G21 G17 G90", "M3 S1000"}
+
+// Lint lines with the LinuxCNC dialect and the default machine
+func lintLines(linter *GCodeLinter, lines []string) []LintFinding {
+	linter.findings = nil
+	linter.checkCommands(parseLines(newLinuxCNCDialect(), lines))
+	return linter.findings
+}
+
+// Check the checks and the lines of the findings
+func checkFindings(t *testing.T, findings []LintFinding, expected ...LintFinding) {
+	t.Helper()
+	if len(findings) != len(expected) {
+		t.Fatalf("got findings %v, expected %v", findings, expected)
+	}
+	for i := range expected {
+		if findings[i].Check != expected[i].Check || findings[i].Line != expected[i].Line {
+			t.Fatalf("got findings %v, expected %v", findings, expected)
+		}
+	}
+}
+
+func TestLintModalStateAndSpindle(t *testing.T) {
+	linter := newGCodeLinter(newLinuxCNCDialect(), defaultMachineConfiguration())
+
+	// Each unset group and the spindle are reported once, on the first move
+	findings := lintLines(linter, []string{"G21", "G1 X1 F100", "G1 X2"})
+	checkFindings(t, findings,
+		LintFinding{Check: "modal-state", Line: 2}, LintFinding{Check: "modal-state", Line: 2},
+		LintFinding{Check: "spindle-off", Line: 2})
+
+	checkFindings(t, lintLines(linter, append(lintHeader, "G1 X1 F100", "G0 Z5")))
+}
+
+func TestLintFeedRate(t *testing.T) {
+	linter := newGCodeLinter(newLinuxCNCDialect(), defaultMachineConfiguration())
+
+	findings := lintLines(linter, append(lintHeader, "G0 X1", "G1 X2", "G1 X3 F100"))
+	checkFindings(t, findings, LintFinding{Check: "feed-rate", Line: 4})
+}
+
+func TestLintArcs(t *testing.T) {
+	linter := newGCodeLinter(newLinuxCNCDialect(), defaultMachineConfiguration())
+
+	findings := lintLines(linter, append(lintHeader,
+		"G0 X0 Y0",
+		"G2 X2 Y0 I1 F100",
+		"G2 X4 Y0 I0.5",
+		"G2 X8 Y0 R1",
+		"G3 X9 Y0",
+	))
+	checkFindings(t, findings,
+		LintFinding{Check: "arc-radius", Line: 5}, LintFinding{Check: "arc-radius", Line: 6},
+		LintFinding{Check: "arc-radius", Line: 7})
+
+	// The radius arc is checked against its own radius, not the center of the
+	// arc before it
+	checkFindings(t, lintLines(linter, append(lintHeader, "G0 X0 Y0", "G2 X2 Y0 I1 F100", "G2 X4 Y0 R1")))
+}
+
+func TestLintTools(t *testing.T) {
+	linter := newGCodeLinter(newLinuxCNCDialect(), defaultMachineConfiguration())
+	linter.setToolCount(4)
+
+	findings := lintLines(linter, append(lintHeader, "T2 M6", "T0 M6", "T5 M6", "T1.5 M6"))
+	checkFindings(t, findings, LintFinding{Check: "tool-number", Line: 5}, LintFinding{Check: "tool-number", Line: 6})
+}
+
+func TestLintSoftLimits(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setSoftLimits(Vector3d{X: -10, Y: -3, Z: -10}, Vector3d{X: 10, Y: 3, Z: 10})
+	linter := newGCodeLinter(newLinuxCNCDialect(), configuration)
+
+	// The arc stays between its ends in X but bulges past the limits in Y
+	findings := lintLines(linter, append(lintHeader, "G0 X-5 Y0", "G2 X5 Y0 I5 F100", "G1 X20"))
+	checkFindings(t, findings, LintFinding{Check: "soft-limits", Line: 4}, LintFinding{Check: "soft-limits", Line: 5})
+}
+
+func TestLintNonFinitePositions(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setSoftLimits(Vector3d{X: -15, Y: -15, Z: -15}, Vector3d{X: 15, Y: 15, Z: 15})
+	linter := newGCodeLinter(newLinuxCNCDialect(), configuration)
+
+	// The radius is too small for the ends of the arc, it has no position
+	findings := lintLines(linter, append(lintHeader, "G2 X20 Y0 R2 F100"))
+	checkFindings(t, findings, LintFinding{Check: "arc-radius", Line: 3}, LintFinding{Check: "soft-limits", Line: 3})
+}
+
+// Write a program in a temporary directory and lint it
+func lintProgram(t *testing.T, linter *GCodeLinter, lines string) []LintFinding {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "program.ngc")
+	if err := os.WriteFile(filename, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := linter.lint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return findings
+}
+
+func TestLintProgramErrors(t *testing.T) {
+	linter := newGCodeLinter(newGrblDialect(), defaultMachineConfiguration())
+
+	// The unsupported code is reported and the lines after it are checked
+	findings := lintProgram(t, linter, "G21 G17 G90\nM3 S1000\nG81 X1 Z-1 R1\nG1 X2\n")
+	checkFindings(t, findings, LintFinding{Check: "unsupported-code", Line: 3}, LintFinding{Check: "feed-rate", Line: 4})
+	if findings[0].Severity != LintError {
+		t.Errorf("got %v, expected an error", findings[0])
+	}
+}
+
+func TestLintEndlessLoop(t *testing.T) {
+	linter := newGCodeLinter(newLinuxCNCDialect(), defaultMachineConfiguration())
+
+	// The loop is a syntax error instead of hanging the linter, the moves
+	// before it are still checked
+	findings := lintProgram(t, linter, "G21 G17 G90\nG1 X1\no1 while [1]\nG1 X2 F100\no1 endwhile\n")
+	checkFindings(t, findings,
+		LintFinding{Check: "spindle-off", Line: 2}, LintFinding{Check: "feed-rate", Line: 2},
+		LintFinding{Check: "syntax", Line: 3})
+}
+
+func TestLintPlanningErrors(t *testing.T) {
+	linter := newGCodeLinter(newLinuxCNCDialect(), defaultMachineConfiguration())
+
+	findings := lintLines(linter, append(lintHeader, "G0 Z5", "G33.1 Z-10 K0"))
+	checkFindings(t, findings, LintFinding{Check: "planning", Line: 4})
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Highest numbered parameter
+const maxParameterIndex = 5602
+
+// Number of arguments of a subroutine call, passed in #1 to #30
+const subroutineArguments = 30
+
+// Reference to a numbered or a named parameter
+type ParameterReference struct {
+	index int
+	name  string
+}
+
+// Numbered and named parameters of a program. Named parameters starting with
+// an underscore are global, the others are local to the subroutine setting them.
+type GCodeParameters struct {
+	numbered map[int]float64
+	global   map[string]float64
+	locals   []map[string]float64
+}
+
+// Create new parameters, all the numbered parameters are 0
+func newGCodeParameters() *GCodeParameters {
+	return &GCodeParameters{
+		numbered: make(map[int]float64),
+		global:   make(map[string]float64),
+		locals:   []map[string]float64{{}},
+	}
+}
+
+// Get the scope of a named parameter
+func (p *GCodeParameters) scope(name string) map[string]float64 {
+	if strings.HasPrefix(name, "_") {
+		return p.global
+	}
+	return p.locals[len(p.locals)-1]
+}
+
+// Check if a named parameter is defined
+func (p *GCodeParameters) exists(name string) bool {
+	_, ok := p.scope(name)[name]
+	return ok
+}
+
+// Get the value of a parameter
+func (p *GCodeParameters) get(reference ParameterReference) (float64, error) {
+	if reference.name != "" {
+		value, ok := p.scope(reference.name)[reference.name]
+		if !ok {
+			return 0, fmt.Errorf("named parameter #<%s> is not defined", reference.name)
+		}
+		return value, nil
+	}
+
+	if reference.index < 0 || reference.index > maxParameterIndex {
+		return 0, fmt.Errorf("parameter #%d is out of range", reference.index)
+	}
+	return p.numbered[reference.index], nil
+}
+
+// Set the value of a parameter
+func (p *GCodeParameters) set(reference ParameterReference, value float64) error {
+	if reference.name != "" {
+		p.scope(reference.name)[reference.name] = value
+		return nil
+	}
+
+	if reference.index < 1 || reference.index > maxParameterIndex {
+		return fmt.Errorf("parameter #%d is out of range", reference.index)
+	}
+	p.numbered[reference.index] = value
+	return nil
+}
+
+// Enter a subroutine, the arguments are in #1 to #30 and the named parameters
+// are local. Return the previous #1 to #30 to restore on exit.
+func (p *GCodeParameters) enterSubroutine(arguments []float64) ([subroutineArguments]float64, error) {
+	var saved [subroutineArguments]float64
+	if len(arguments) > subroutineArguments {
+		return saved, fmt.Errorf("too many arguments, %d given for at most %d", len(arguments), subroutineArguments)
+	}
+
+	for i := range saved {
+		saved[i] = p.numbered[i+1]
+		p.numbered[i+1] = 0
+	}
+	for i, argument := range arguments {
+		p.numbered[i+1] = argument
+	}
+	p.locals = append(p.locals, map[string]float64{})
+
+	return saved, nil
+}
+
+// Exit a subroutine, restoring #1 to #30 and the named parameters of the caller
+func (p *GCodeParameters) exitSubroutine(saved [subroutineArguments]float64) {
+	for i, value := range saved {
+		p.numbered[i+1] = value
+	}
+	p.locals = p.locals[:len(p.locals)-1]
+}
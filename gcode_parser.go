@@ -13,107 +13,462 @@ type GCodeParser struct {
 	previous_position Vector3d
 	previous_feedrate float64
 	lastParams        map[string]float64
-	allowedParamsMap  map[string]map[string]bool
+	dialect           *GCodeDialect
+
+	// Skip the lines starting with a slash
+	blockDelete bool
+
+	// In inverse time mode (G93), the feed moves need an F word on their line
+	inverseTime bool
+
+	// N number of the line being parsed, -1 without one
+	lineNumber int
+
+	// Location of the line being parsed in the program, line 0 for the
+	// startup code of the dialect
+	file string
+	line int
+
+	// Receives the diagnostics, printed when nil
+	reporter func(GCodeDiagnostic)
 }
 
 type GCodeCommand struct {
 	description string
 	command     string
 	params      map[string]float64
+
+	// Letters of the words written on the line, the other parameters are
+	// carried from the previous lines
+	lineWords map[string]bool
+
+	// N number of the line, -1 without one
+	lineNumber int
+
+	// Location of the line in the program
+	file string
+	line int
 }
 
-// New GCode Parser
-func newGCodeParser() *GCodeParser {
-	var allowedParamsMap = map[string]map[string]bool{
-		"G0": {"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true, "F": true},
-		"G1": {"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true, "F": true},
-		"G2": {"X": true, "Y": true, "Z": true, "I": true, "J": true, "K": true, "R": true, "P": true, "F": true},
-		"G3": {"X": true, "Y": true, "Z": true, "I": true, "J": true, "K": true, "R": true, "P": true, "F": true},
-		/*"G4":  {"P": true, "S": true},
-		"G20": {},
-		"G21": {},
-		"G28": {"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true},
-		"G30": {"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true},
-		"G90": {},
-		"G91": {},
-		"G92": {"X": true, "Y": true, "Z": true, "A": true, "B": true, "C": true, "U": true, "V": true, "W": true, "E": true},
-		"G93": {},
-		"G94": {},
-		"M0":  {"P": true},
-		"M1":  {"P": true},
-		"M2":  {"P": true},
-		"M30": {"P": true},
-		"M3":  {"S": true},
-		"M4":  {"S": true},
-		"M5":  {"S": true},
-		"M6":  {"T": true},
-		"M7":  {"P": true},
-		"M8":  {"P": true},
-		"M9":  {"P": true},
-		"M48": {},
-		"M49": {},*/
+// Motion commands are modal and are reused by lines containing only parameters
+var motionCommands = map[string]bool{
+	"G0": true, "G1": true, "G2": true, "G3": true,
+	"G73": true, "G80": true, "G81": true, "G82": true, "G83": true, "G84": true,
+	"G85": true, "G86": true, "G87": true, "G88": true, "G89": true,
+	"G38.2": true, "G38.3": true, "G38.4": true, "G38.5": true,
+	"G33": true, "G33.1": true, "G76": true,
+}
+
+// Non-motion commands that use the axis words of their line
+var axisWordCommands = map[string]bool{"G28": true, "G30": true, "G92": true}
+
+// The axis words of the reference returns are an intermediate point, they
+// only apply to their line
+var referenceReturnCommands = map[string]bool{"G28": true, "G30": true}
+
+// Insert the distance mode before the reference returns of its line, their
+// intermediate point is in the distance mode of the line
+func insertDistanceMode(commands []string, distanceMode string) []string {
+	for i, command := range commands {
+		if referenceReturnCommands[command] {
+			return append(commands[:i], append([]string{distanceMode}, commands[i:]...)...)
+		}
 	}
+	return append(commands, distanceMode)
+}
 
-	var GCodeParser = GCodeParser{
+// The S word of the temperature codes is a temperature, not the spindle speed
+var temperatureCommands = map[string]bool{"M104": true, "M109": true, "M140": true, "M190": true}
+
+// Words the commands require on their own line, the carried ones are not used
+var requiredWords = map[string]string{"G4": "P", "G33": "K", "G33.1": "K", "G76": "PK", "M62": "P", "M63": "P", "M64": "P", "M65": "P"}
 
-		allowedParamsMap: allowedParamsMap,
+// New GCode Parser, for the LinuxCNC dialect
+func newGCodeParser() *GCodeParser {
+	var GCodeParser = GCodeParser{
+		dialect:    newLinuxCNCDialect(),
+		lineNumber: -1,
 	}
 
 	return &GCodeParser
 }
 
-func (p *GCodeParser) parseCommand(line string) *GCodeCommand {
+// Set the dialect of the programs to parse
+func (p *GCodeParser) setDialect(dialect *GCodeDialect) {
+	p.dialect = dialect
+	p.lastCommand = dialect.motionMode
+}
+
+// Get the dialect of the programs to parse
+func (p *GCodeParser) getDialect() *GCodeDialect {
+	return p.dialect
+}
+
+// Reset the modal state to the one of the dialect at the start of a program,
+// and return the commands of its startup code
+func (p *GCodeParser) startProgram() []GCodeCommand {
+	p.lastParams = nil
+	p.lineNumber = -1
+	p.inverseTime = false
+	p.line = 0
+	var commands []GCodeCommand
+	if p.dialect.startupCode != "" {
+		commands = p.parseBlock(p.dialect.startupCode)
+	}
+	p.lastCommand = p.dialect.motionMode
+	return commands
+}
+
+// Set the location of the next lines in the program
+func (p *GCodeParser) setLocation(file string, line int) {
+	p.file = file
+	p.line = line
+}
+
+// Set the function receiving the diagnostics instead of printing them
+func (p *GCodeParser) setReporter(reporter func(GCodeDiagnostic)) {
+	p.reporter = reporter
+}
+
+// Report a diagnostic on the line being parsed
+func (p *GCodeParser) report(message string, unsupported bool, warning bool) {
+	diagnostic := GCodeDiagnostic{file: p.file, line: p.line, lineNumber: p.lineNumber, message: message, unsupported: unsupported, warning: warning}
+	if p.reporter != nil {
+		p.reporter(diagnostic)
+		return
+	}
+	fmt.Println(diagnostic)
+}
+
+// Report an unknown word as the dialect asks. Return true if the line is rejected.
+func (p *GCodeParser) unknownWord(message string) bool {
+	switch p.dialect.unknownWords {
+	case UnknownWordError:
+		p.report(message, true, false)
+		return true
+	case UnknownWordWarn:
+		p.report(message, true, true)
+	}
+	return false
+}
+
+// Create a command of the line being parsed
+func (p *GCodeParser) newCommand(description string, command string, params map[string]float64) GCodeCommand {
+	return GCodeCommand{description: description, command: command, params: params, lineNumber: p.lineNumber, file: p.file, line: p.line}
+}
+
+// Check if a word is written on the line of the command, rather than carried
+// from the previous lines
+func (c GCodeCommand) isOnLine(letter string) bool {
+	return c.lineWords[letter]
+}
+
+// Enable or disable the block delete switch, skipping the lines starting with a slash
+func (p *GCodeParser) setBlockDelete(blockDelete bool) {
+	p.blockDelete = blockDelete
+}
+
+// Prepare a line for parsing: remove the block delete slash, validate and
+// remove the *checksum of RepRap senders and remove the N number. Return the
+// rest of the line, or skip it if it is deleted.
+func (p *GCodeParser) prepareLine(line string) (string, bool, error) {
+	line = strings.TrimSpace(line)
+	p.lineNumber = -1
+
+	if strings.HasPrefix(line, "/") {
+		if p.blockDelete {
+			return "", true, nil
+		}
+		line = strings.TrimSpace(line[1:])
+	}
 
-	if len(line) == 0 || line[0] == ';' || line[0] == '(' || line[0] == '%' {
-		return &GCodeCommand{line, "Comment", nil}
+	// The checksum is the exclusive or of the characters before the star
+	if star, expected, ok := findChecksum(line); ok {
+		checksum := 0
+		for i := 0; i < star; i++ {
+			checksum ^= int(line[i])
+		}
+		line = strings.TrimSpace(line[:star])
+		if checksum != expected {
+			p.parseLineNumber(line)
+			return "", false, fmt.Errorf("checksum mismatch, the line gives %d but its characters give %d", expected, checksum)
+		}
+	}
+
+	return p.parseLineNumber(line), false, nil
+}
+
+// Find the checksum of a line, a star followed by digits ending its code. The
+// stars in the comments and in the expressions are not checksums.
+func findChecksum(line string) (int, int, bool) {
+	star, end := -1, len(line)
+	for i := 0; i < len(line) && end == len(line); i++ {
+		switch line[i] {
+		case '(':
+			if close := strings.IndexByte(line[i:], ')'); close >= 0 {
+				i += close
+			}
+		case ';':
+			end = i
+		case '*':
+			star = i
+		}
+	}
+	if star < 0 {
+		return 0, 0, false
 	}
 
-	tokens := strings.Fields(line)
-	if len(tokens) < 1 {
-		fmt.Println("Empty line")
+	digits := strings.TrimSpace(line[star+1 : end])
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return 0, 0, false
+	}
+	checksum, err := strconv.Atoi(digits)
+	return star, checksum, err == nil
+}
+
+// Remove the N number at the start of a line and keep it as the current line number
+func (p *GCodeParser) parseLineNumber(line string) string {
+	if len(line) < 2 || (line[0] != 'N' && line[0] != 'n') {
+		return line
+	}
+
+	digits, end := scanNumber(line, 1)
+	number, err := strconv.Atoi(digits)
+	if err != nil {
+		return line
+	}
+
+	p.lineNumber = number
+	return strings.TrimSpace(line[end:])
+}
+
+// Parse a line into its commands. Modal commands on the line come first and
+// the motion command, if any, comes last.
+func (p *GCodeParser) parseCommand(line string) []GCodeCommand {
+	line, skip, err := p.prepareLine(line)
+	if err != nil {
+		p.report(err.Error(), false, false)
+		return nil
+	}
+	if skip {
+		return nil
+	}
+
+	return p.parseBlock(line)
+}
+
+// Parse a prepared line into its commands, with the current line number
+func (p *GCodeParser) parseBlock(line string) []GCodeCommand {
+
+	if len(line) == 0 || line[0] == '%' {
+		return []GCodeCommand{p.newCommand(line, "Comment", nil)}
+	}
+
+	code, comments, err := splitComments(line)
+	if err != nil {
+		p.report(err.Error(), false, false)
 		return nil
 	}
 
-	command := tokens[0]
-	if !strings.HasPrefix(command, "G") {
-		command = p.lastCommand
-	} else {
-		tokens = tokens[1:]
+	// Message comments come first, their description is the message text
+	var gcodeCommands []GCodeCommand
+	for _, comment := range comments {
+		if messageType, text, ok := parseOperatorMessage(comment); ok {
+			gcodeCommands = append(gcodeCommands, p.newCommand(text, messageType.String(), nil))
+		}
+	}
+	if strings.TrimSpace(code) == "" {
+		if len(gcodeCommands) == 0 {
+			return []GCodeCommand{p.newCommand(line, "Comment", nil)}
+		}
+		return gcodeCommands
+	}
+
+	words, err := tokenizeLine(code)
+	if err != nil {
+		p.report(err.Error(), false, false)
+		return nil
 	}
 
 	params := make(map[string]float64)
 	for key, val := range p.lastParams {
 		params[key] = val
 	}
+	lineParams := make(map[string]float64)
+
+	var commands []string
+	motionCommand := ""
+	hasAxisWords := false
+	usesAxisWords := false
+
+	for _, word := range words {
+		if word.letter == 'G' || word.letter == 'M' {
+			command := word.String()
+			if !p.dialect.accepts(command) {
+				if p.unknownWord(fmt.Sprintf("unsupported code in the %s dialect: %s", p.dialect.getName(), command)) {
+					return nil
+				}
+				continue
+			}
+
+			if motionCommands[command] {
+				motionCommand = command
+			} else if command == "G90" || command == "G91" {
+				commands = insertDistanceMode(commands, command)
+			} else {
+				commands = append(commands, command)
+			}
+			if axisWordCommands[command] {
+				usesAxisWords = true
+			}
+			continue
+		}
 
-	for _, token := range tokens {
-		if len(token) < 2 {
-			fmt.Println("invalid token: %s", token)
-			return nil
+		if strings.IndexByte("XYZABCUVWE", word.letter) >= 0 {
+			hasAxisWords = true
 		}
 
-		val, err := strconv.ParseFloat(token[1:], 64)
-		if err != nil {
-			fmt.Println("invalid value: %s", token[1:])
+		params[string(word.letter)] = word.value
+		lineParams[string(word.letter)] = word.value
+	}
+
+	// Axis words without a motion command continue the last motion
+	if motionCommand == "" && hasAxisWords && !usesAxisWords {
+		motionCommand = p.lastCommand
+	}
+
+	if motionCommand != "" {
+		commands = append(commands, motionCommand)
+		p.lastCommand = motionCommand
+	}
+
+	// Words not used by any command of the line, other than the modal ones, are unknown
+	for letter := range lineParams {
+		used := strings.Contains(modalWords, letter)
+		for _, command := range commands {
+			used = used || p.dialect.acceptsWord(command, letter)
+		}
+		if !used && p.unknownWord(fmt.Sprintf("unsupported word in the %s dialect: %s", p.dialect.getName(), letter)) {
 			return nil
 		}
+	}
 
-		params[string(token[0])] = val
+	for _, command := range commands {
+		for _, letter := range requiredWords[command] {
+			if _, ok := lineParams[string(letter)]; !ok {
+				p.report(fmt.Sprintf("%s requires a %c word", command, letter), false, false)
+				return nil
+			}
+		}
 	}
 
-	p.lastCommand = command
-	p.lastParams = params
+	// The F word of an inverse time move is the time of its block, it is not carried
+	for _, command := range commands {
+		switch command {
+		case "G93":
+			p.inverseTime = true
+		case "G94", "G95":
+			p.inverseTime = false
+		}
+	}
+	if _, ok := lineParams["F"]; !ok && p.inverseTime && (motionCommand == "G1" || motionCommand == "G2" || motionCommand == "G3") {
+		p.report(fmt.Sprintf("%s requires an F word in inverse time mode (G93)", motionCommand), false, false)
+		return nil
+	}
 
-	validParams := p.validateAndFilterParams(command, params)
+	lineWords := make(map[string]bool)
+	for letter := range lineParams {
+		lineWords[letter] = true
+	}
+	for _, command := range commands {
+		commandParams := params
+		if referenceReturnCommands[command] {
+			commandParams = lineParams
+		}
+		validParams := p.validateAndFilterParams(command, commandParams)
+
+		// Dialect specific codes are translated to the commands of the planner
+		command, ok := p.dialect.translate(command, validParams)
+		if ok {
+			gcodeCommand := p.newCommand(line, command, validParams)
+			gcodeCommand.lineWords = lineWords
+			gcodeCommands = append(gcodeCommands, gcodeCommand)
+		}
+	}
+
+	p.lastParams = carryWords(p.lastParams, lineParams, commands, isCannedCycle(p.lastCommand))
 
-	return &GCodeCommand{line, command, validParams}
+	return gcodeCommands
+}
+
+// Tell if the parser carries a word of a line with these codes to the next
+// lines. The dwell, peck increment and retract plane are only modal while a
+// canned cycle is the motion mode after the line. The arc offsets, the repeat
+// count, the extruder position and the tool offset never are, the
+// temperatures are not carried to the spindle and the intermediate point of a
+// reference return is not carried.
+func carriesWord(key string, codes []string, cannedCycle bool) bool {
+	for _, code := range codes {
+		if temperatureCommands[code] && key == "S" || referenceReturnCommands[code] && strings.Contains("XYZABCUVW", key) {
+			return false
+		}
+	}
+	switch key {
+	case "I", "J", "K", "L", "E", "H":
+		return false
+	case "P", "Q", "R":
+		return cannedCycle
+	}
+	return true
+}
+
+// Get the words carried after a line with these codes, the words of the line
+// replace the carried ones when the parser carries them. The words of the
+// canned cycles are dropped once they end.
+func carryWords[T any](carried map[string]T, lineWords map[string]T, codes []string, cannedCycle bool) map[string]T {
+	words := make(map[string]T)
+	for key, val := range carried {
+		if cannedCycle || !strings.Contains("PQR", key) {
+			words[key] = val
+		}
+	}
+	for key, val := range lineWords {
+		if carriesWord(key, codes, cannedCycle) {
+			words[key] = val
+		}
+	}
+	return words
+}
+
+// Split a line into its code and the text of its parenthesized comments. A
+// semicolon comments out the rest of the line.
+func splitComments(line string) (string, []string, error) {
+	var code strings.Builder
+	var comments []string
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '(':
+			end := strings.IndexByte(line[i:], ')')
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated comment: %s", line[i:])
+			}
+			comments = append(comments, line[i+1:i+end])
+			code.WriteByte(' ')
+			i += end
+		case ';':
+			return code.String(), comments, nil
+		default:
+			code.WriteByte(line[i])
+		}
+	}
+	return code.String(), comments, nil
 }
 
 func (p *GCodeParser) validateAndFilterParams(command string, params map[string]float64) map[string]float64 {
 	validParams := make(map[string]float64)
 
 	for key, val := range params {
-		if allowed, ok := p.allowedParamsMap[command][key]; ok && allowed {
+		if p.dialect.acceptsWord(command, key) {
 			validParams[key] = val
 		}
 	}
@@ -123,14 +478,11 @@ func (p *GCodeParser) validateAndFilterParams(command string, params map[string]
 
 func (g *GCodeParser) fromString(gcodeStringList []string) []GCodeCommand {
 
-	var gcodeLines []GCodeCommand
-
-	for _, line := range gcodeStringList {
-		gcodeLine := g.parseCommand(line)
+	gcodeLines := g.startProgram()
 
-		if gcodeLine != nil {
-			gcodeLines = append(gcodeLines, *gcodeLine)
-		}
+	for i, line := range gcodeStringList {
+		g.setLocation(g.file, i+1)
+		gcodeLines = append(gcodeLines, g.parseCommand(line)...)
 	}
 
 	return gcodeLines
@@ -157,6 +509,7 @@ func (g *GCodeParser) fromFile(filename string) []GCodeCommand {
 		gcodeStringList = append(gcodeStringList, line)
 	}
 
+	g.setLocation(filename, 0)
 	gCodeList := g.fromString(gcodeStringList)
 
 	return gCodeList
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Parse lines with the LinuxCNC dialect and collect the diagnostics
+func parseWithDiagnostics(lines []string) ([]GCodeCommand, []GCodeDiagnostic) {
+	parser := newGCodeParser()
+	var diagnostics []GCodeDiagnostic
+	parser.setReporter(func(diagnostic GCodeDiagnostic) {
+		diagnostics = append(diagnostics, diagnostic)
+	})
+	return parser.fromString(lines), diagnostics
+}
+
+// Check that the diagnostics are on the given lines
+func checkDiagnosticLines(t *testing.T, diagnostics []GCodeDiagnostic, lines ...int) {
+	t.Helper()
+	if len(diagnostics) != len(lines) {
+		t.Fatalf("got diagnostics %v, expected them on lines %v", diagnostics, lines)
+	}
+	for i, line := range lines {
+		if diagnostics[i].line != line {
+			t.Fatalf("got diagnostics %v, expected them on lines %v", diagnostics, lines)
+		}
+	}
+}
+
+// Check if a command was parsed
+func hasCommand(commands []GCodeCommand, command string) bool {
+	for _, parsed := range commands {
+		if parsed.command == command {
+			return true
+		}
+	}
+	return false
+}
+
+func TestParserInverseTimeRequiresF(t *testing.T) {
+	commands, diagnostics := parseWithDiagnostics([]string{"G1 X1 F100", "G93 G1 X2 F2", "X3", "G2 X4 I1", "G0 X0", "G94 G1 X5"})
+
+	checkDiagnosticLines(t, diagnostics, 3, 4)
+	if hasCommand(commands, "G2") {
+		t.Errorf("the G2 without F was parsed")
+	}
+}
+
+// Add the checksum of a line at its end
+func withChecksum(line string) string {
+	checksum := 0
+	for i := 0; i < len(line); i++ {
+		checksum ^= int(line[i])
+	}
+	return fmt.Sprintf("%s*%d", line, checksum)
+}
+
+func TestParserChecksums(t *testing.T) {
+	commands, diagnostics := parseWithDiagnostics([]string{
+		withChecksum("N1 G1 X10 F100"),
+		"N2 G1 X20 ; feed * 2",
+		withChecksum("N3 G1 X30") + " ; comment",
+		"N4 G1 X40*1 ; comment",
+		"N5 G1 X50 (a * 3)",
+	})
+
+	// Only the stars followed by digits at the end of the code are checksums
+	checkDiagnosticLines(t, diagnostics, 4)
+	var moves []float64
+	for _, command := range commands {
+		if command.command == "G1" {
+			moves = append(moves, command.params["X"])
+		}
+	}
+	if fmt.Sprint(moves) != fmt.Sprint([]float64{10, 20, 30, 50}) {
+		t.Errorf("got moves %v, expected X10, X20, X30 and X50", moves)
+	}
+}
+
+func TestParserBlockDeleteAndLineNumbers(t *testing.T) {
+	lines := []string{"N10 G0 X1", "/N20 G0 X2", "n30 G0 X3"}
+
+	parser := newGCodeParser()
+	commands := parser.fromString(lines)
+	var numbers []int
+	for _, command := range commands {
+		if command.command == "G0" {
+			numbers = append(numbers, command.lineNumber)
+		}
+	}
+	if len(numbers) != 3 || numbers[0] != 10 || numbers[1] != 20 || numbers[2] != 30 {
+		t.Errorf("got line numbers %v, expected [10 20 30]", numbers)
+	}
+
+	// With block delete on, the lines starting with a slash are skipped
+	parser = newGCodeParser()
+	parser.setBlockDelete(true)
+	numbers = nil
+	for _, command := range parser.fromString(lines) {
+		if command.command == "G0" {
+			numbers = append(numbers, command.lineNumber)
+		}
+	}
+	if len(numbers) != 2 || numbers[0] != 10 || numbers[1] != 30 {
+		t.Errorf("got line numbers %v with block delete, expected [10 30]", numbers)
+	}
+}
+
+func TestParserCarriedWords(t *testing.T) {
+	commands, diagnostics := parseWithDiagnostics([]string{
+		"G2 X10 Y0 I5 F100",
+		"G2 X30 J0",
+		"G82 X1 Z-1 R2 P0.5",
+		"X2",
+		"G80",
+		"G1 X3",
+		"G4 P1",
+	})
+	checkDiagnosticLines(t, diagnostics)
+
+	// The arc offsets are not carried to the next arc
+	var arcs []GCodeCommand
+	var cycles []GCodeCommand
+	for _, command := range commands {
+		switch command.command {
+		case "G2":
+			arcs = append(arcs, command)
+		case "G82":
+			cycles = append(cycles, command)
+		case "G1":
+			if _, ok := command.params["P"]; ok {
+				t.Errorf("the dwell of the cycle reached %v after G80", command)
+			}
+			if _, ok := command.params["R"]; ok {
+				t.Errorf("the retract plane of the cycle reached %v after G80", command)
+			}
+		}
+	}
+	if len(arcs) != 2 {
+		t.Fatalf("got arcs %v, expected 2", arcs)
+	}
+	if _, ok := arcs[1].params["I"]; ok {
+		t.Errorf("the I of the first arc reached %v", arcs[1])
+	}
+
+	// The words of a canned cycle are carried while it repeats
+	if len(cycles) != 2 || cycles[1].params["P"] != 0.5 || cycles[1].params["R"] != 2 {
+		t.Errorf("got cycles %v, expected the second one to repeat R2 P0.5", cycles)
+	}
+}
+
+func TestParserDwellRequiresP(t *testing.T) {
+	commands, diagnostics := parseWithDiagnostics([]string{"G82 X1 Z-1 R2 P0.5", "G4"})
+	checkDiagnosticLines(t, diagnostics, 2)
+	if hasCommand(commands, "G4") {
+		t.Errorf("the G4 without P was parsed")
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+// Subroutine of a program, between its sub and endsub lines, or numbered
+// program between its O number and M99 lines
+type Subroutine struct {
+	name  string
+	file  string
+	lines []string
+	start int
+	end   int
+}
+
+// Create a new subroutine from the lines of its file and the index of its first and last lines
+func newSubroutine(name string, file string, lines []string, start int, end int) *Subroutine {
+	return &Subroutine{name: name, file: file, lines: lines, start: start, end: end}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Order of the words after the codes of a block
+const writerWordOrder = "XYZABCUVWEIJKRPQLHFST"
+
+// Write parsed commands back to G-code for a dialect, one block per line of
+// the program. The commands the dialect cannot express are left out.
+type GCodeWriter struct {
+	dialect *GCodeDialect
+
+	// Number of decimals of the values, their trailing zeros are removed
+	precision int
+
+	// Leave out the motion codes and the words the parser carries from the
+	// previous blocks
+	modalElision bool
+}
+
+// Create a new G-code writer for a dialect, with 4 decimals and without modal elision
+func newGCodeWriter(dialect *GCodeDialect) *GCodeWriter {
+	return &GCodeWriter{dialect: dialect, precision: 4}
+}
+
+// Set the number of decimals of the values
+func (w *GCodeWriter) setPrecision(precision int) {
+	w.precision = precision
+}
+
+// Enable or disable the modal elision
+func (w *GCodeWriter) setModalElision(modalElision bool) {
+	w.modalElision = modalElision
+}
+
+// Format a value with the precision of the writer, without its trailing zeros
+func (w *GCodeWriter) formatValue(value float64) string {
+	text := strconv.FormatFloat(value, 'f', w.precision, 64)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	if text == "-0" {
+		return "0"
+	}
+	return text
+}
+
+// Check if a command is written on a line of its own
+func isStandaloneCommand(command string) bool {
+	_, isMessage := operatorMessageTypeFromCommand(command)
+	return command == "Comment" || isMessage
+}
+
+// Group the commands into the blocks they were parsed from. The commands of
+// a block share their line, and its motion command, if any, comes last.
+func groupBlocks(commands []GCodeCommand) [][]GCodeCommand {
+	var blocks [][]GCodeCommand
+	var block []GCodeCommand
+	for _, command := range commands {
+		sameBlock := len(block) > 0 && !isStandaloneCommand(command.command) && !isStandaloneCommand(block[0].command) &&
+			command.description == block[0].description && command.lineNumber == block[0].lineNumber &&
+			!motionCommands[block[len(block)-1].command]
+		for _, previous := range block {
+			sameBlock = sameBlock && previous.command != command.command
+		}
+
+		if !sameBlock && len(block) > 0 {
+			blocks = append(blocks, block)
+			block = nil
+		}
+		block = append(block, command)
+	}
+	if len(block) > 0 {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// Write the commands to G-code lines. The startup code of the dialect at the
+// start of the commands is left out, the parser adds it back.
+func (w *GCodeWriter) write(commands []GCodeCommand) []string {
+	// Values carried by the parser from the previous blocks, as written
+	modal := make(map[string]string)
+	motion := w.dialect.motionMode
+	inverseTime := false
+
+	var lines []string
+	for i, block := range groupBlocks(commands) {
+		if i == 0 && block[0].lineNumber < 0 && block[0].description == w.dialect.startupCode {
+			continue
+		}
+
+		prefix := ""
+		if block[0].lineNumber >= 0 {
+			prefix = fmt.Sprintf("N%d ", block[0].lineNumber)
+		}
+
+		if block[0].command == "Comment" {
+			lines = append(lines, strings.TrimSpace(prefix+block[0].description))
+			continue
+		}
+		if messageType, ok := operatorMessageTypeFromCommand(block[0].command); ok {
+			lines = append(lines, fmt.Sprintf("%s(%s,%s)", prefix, messageType, block[0].description))
+			continue
+		}
+
+		line, carried, ok := w.writeBlock(block, modal, &motion, &inverseTime)
+		modal = carried
+		if ok {
+			lines = append(lines, prefix+line)
+		}
+	}
+
+	return lines
+}
+
+// Write the codes and words of a block, and get the words the parser carries
+// after it. The feed moves in inverse time mode (G93) always have their F. Return false if no command of the block is expressed by the
+// dialect.
+func (w *GCodeWriter) writeBlock(block []GCodeCommand, modal map[string]string, motion *string, inverseTime *bool) (string, map[string]string, bool) {
+	var codes []string
+	words := make(map[string]string)
+	blockMotion := ""
+	usesAxisWords := false
+
+	for _, command := range block {
+		code, ok := w.dialect.untranslate(command.command, command.params)
+		if !ok {
+			continue
+		}
+		codes = append(codes, code)
+		if motionCommands[code] {
+			blockMotion = code
+		}
+		usesAxisWords = usesAxisWords || axisWordCommands[code]
+		if code == "G93" || code == "G94" || code == "G95" {
+			*inverseTime = code == "G93"
+		}
+
+		for key, val := range command.params {
+			if w.dialect.acceptsWord(code, key) {
+				words[key] = w.formatValue(val * w.dialect.getWordScale(code, key))
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return "", modal, false
+	}
+	nextMotion := *motion
+	if blockMotion != "" {
+		nextMotion = blockMotion
+	}
+	cannedCycle := isCannedCycle(nextMotion)
+
+	// The words equal to the carried ones are left out, except the ones the
+	// parser does not carry for the codes of the block and the ones the codes
+	// require on their line
+	required := ""
+	for _, code := range codes {
+		required += requiredWords[code]
+	}
+	if *inverseTime && (blockMotion == "G1" || blockMotion == "G2" || blockMotion == "G3") {
+		required += "F"
+	}
+	var written []string
+	hasAxisWords := false
+	for _, letter := range writerWordOrder {
+		key := string(letter)
+		val, ok := words[key]
+		if !ok {
+			continue
+		}
+		if w.modalElision && carriesWord(key, codes, cannedCycle) && !strings.Contains(required, key) && modal[key] == val {
+			continue
+		}
+		written = append(written, key+val)
+		hasAxisWords = hasAxisWords || strings.Contains("XYZABCUVWE", key)
+	}
+
+	// The motion code is left out when axis words continue the same motion
+	if w.modalElision && blockMotion != "" && blockMotion == *motion && hasAxisWords && !usesAxisWords {
+		codes = codes[:len(codes)-1]
+	}
+
+	*motion = nextMotion
+
+	return strings.Join(append(codes, written...), " "), carryWords(modal, words, codes, cannedCycle), true
+}
+
+// Write the commands to a G-code file
+func (w *GCodeWriter) toFile(filename string, commands []GCodeCommand) error {
+	return os.WriteFile(filename, []byte(strings.Join(w.write(commands), "\n")+"\n"), 0644)
+}
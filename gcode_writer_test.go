@@ -0,0 +1,150 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Parse lines with a new parser of a dialect
+func parseLines(dialect *GCodeDialect, lines []string) []GCodeCommand {
+	parser := newGCodeParser()
+	parser.setDialect(dialect)
+	return parser.fromString(lines)
+}
+
+// Check that two command lists have the same commands, words and line numbers
+func checkSameCommands(t *testing.T, expected []GCodeCommand, actual []GCodeCommand) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("got %d commands, expected %d", len(actual), len(expected))
+	}
+	for i := range expected {
+		if expected[i].command != actual[i].command || expected[i].lineNumber != actual[i].lineNumber ||
+			(expected[i].command != "Comment" && !reflect.DeepEqual(expected[i].params, actual[i].params)) {
+			t.Fatalf("command %d: got %v, expected %v", i, actual[i], expected[i])
+		}
+	}
+}
+
+// Check that parse, write and parse gives the same commands, and that
+// writing them again gives the same lines
+func checkRoundTrip(t *testing.T, dialect *GCodeDialect, lines []string, modalElision bool) []string {
+	t.Helper()
+	writer := newGCodeWriter(dialect)
+	writer.setModalElision(modalElision)
+
+	commands := parseLines(dialect, lines)
+	written := writer.write(commands)
+	reparsed := parseLines(dialect, written)
+	checkSameCommands(t, commands, reparsed)
+
+	if rewritten := writer.write(reparsed); !reflect.DeepEqual(written, rewritten) {
+		t.Fatalf("written again as %q, expected %q", rewritten, written)
+	}
+	return written
+}
+
+func TestWriterRoundTripProgram(t *testing.T) {
+	lines, err := readLines("test.gcode")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, modalElision := range []bool{false, true} {
+		checkRoundTrip(t, newLinuxCNCDialect(), lines, modalElision)
+	}
+}
+
+func TestWriterRoundTripBlocks(t *testing.T) {
+	lines := []string{
+		"%",
+		"(header)",
+		"N10 g1x10y-.5f200",
+		"N20 X12 ; continue the feed",
+		"(MSG, Check the clamps) G0 Z5",
+		"G28 G91 Z0.",
+		"G90 G0 X0 Y0",
+		"G43 Z0.4 H1",
+		"G98 G73 X1 Y1 Z-1 R1 Q0.5 L2",
+		"X2",
+		"G80",
+		"G4 P0.5",
+		"G2 X6 Y1 I1 J0 F100",
+		"G2 X8 Y1 I1",
+		"G1 X3 E1.5 F300",
+		"G1 X4",
+		"G1 X4",
+		"G93 G1 X1 F2",
+		"G1 X2 F2",
+		"G94 G1 X3 F300",
+		"M3 S5000",
+		"G92 X0",
+		"M30",
+	}
+
+	for _, modalElision := range []bool{false, true} {
+		checkRoundTrip(t, newLinuxCNCDialect(), lines, modalElision)
+	}
+}
+
+func TestWriterModalElision(t *testing.T) {
+	writer := newGCodeWriter(newLinuxCNCDialect())
+	writer.setModalElision(true)
+
+	written := writer.write(parseLines(newLinuxCNCDialect(), []string{"G1 X1 Y2 F100", "G1 X3 Y2 F100", "G1 X3", "G0 X0"}))
+	expected := []string{"G1 X1 Y2 F100", "X3", "G1", "G0 X0"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Fatalf("got %q, expected %q", written, expected)
+	}
+}
+
+func TestWriterModalElisionKeepsUncarriedWords(t *testing.T) {
+	// The tool offset, the intermediate point of a reference return, the
+	// dwell outside canned cycles and the temperatures are written again
+	writer := newGCodeWriter(newLinuxCNCDialect())
+	writer.setModalElision(true)
+
+	written := writer.write(parseLines(newLinuxCNCDialect(), []string{"G43 H1", "G43 H1", "G0 Z5", "G28 Z5", "G82 Z-1 R1 P0.5", "G4 P0.5"}))
+	expected := []string{"G43 H1", "G43 H1", "G0 Z5", "G28 Z5", "G82 Z-1 R1 P0.5", "G4 P0.5"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Fatalf("got %q, expected %q", written, expected)
+	}
+
+	marlin := newGCodeWriter(newMarlinDialect())
+	marlin.setModalElision(true)
+	written = marlin.write(parseLines(newMarlinDialect(), []string{"M3 S200", "M104 S200", "M109 S200"}))
+	expected = []string{"M3 S200", "M104 S200", "M109 S200"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Fatalf("got %q, expected %q", written, expected)
+	}
+	checkRoundTrip(t, newMarlinDialect(), []string{"M3 S200", "M104 S210", "G1 X1 F100", "M109 S210"}, true)
+}
+
+func TestWriterPrecision(t *testing.T) {
+	writer := newGCodeWriter(newLinuxCNCDialect())
+	writer.setPrecision(3)
+
+	written := writer.write(parseLines(newLinuxCNCDialect(), []string{"G1 X1.23456 Y-0.0001 Z10.000 F1500"}))
+	expected := []string{"G1 X1.235 Y0 Z10 F1500"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Fatalf("got %q, expected %q", written, expected)
+	}
+}
+
+func TestWriterRepost(t *testing.T) {
+	commands := parseLines(newLinuxCNCDialect(), []string{"G64 P0.01", "G1 X1 F100", "G28", "M30"})
+
+	// grbl has no path blending nor G98, G28 without axes returns all of them
+	written := newGCodeWriter(newGrblDialect()).write(commands)
+	expected := []string{"G17 G21 G40 G49 G54 G90 G94", "G1 X1 F100", "G28", "M30"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Fatalf("got %q, expected %q", written, expected)
+	}
+
+	// Fanuc only returns the named axes
+	written = newGCodeWriter(newFanucDialect()).write(commands)
+	expected = []string{"G64 P0.01", "G1 X1 F100", "M30"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Fatalf("got %q, expected %q", written, expected)
+	}
+}
@@ -75,6 +75,890 @@ func (x *Person) GetAge() int32 {
 	return 0
 }
 
+type Vector3D struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X float64 `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y float64 `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	Z float64 `protobuf:"fixed64,3,opt,name=z,proto3" json:"z,omitempty"`
+}
+
+func (x *Vector3D) Reset() {
+	*x = Vector3D{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Vector3D) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vector3D) ProtoMessage() {}
+
+func (x *Vector3D) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vector3D.ProtoReflect.Descriptor instead.
+func (*Vector3D) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Vector3D) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Vector3D) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Vector3D) GetZ() float64 {
+	if x != nil {
+		return x.Z
+	}
+	return 0
+}
+
+// Move motors toward or away from their homing switches
+type HomingMove struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Motors       []int32   `protobuf:"varint,1,rep,packed,name=motors,proto3" json:"motors,omitempty"`
+	Distances    []float64 `protobuf:"fixed64,2,rep,packed,name=distances,proto3" json:"distances,omitempty"`
+	Velocity     float64   `protobuf:"fixed64,3,opt,name=velocity,proto3" json:"velocity,omitempty"`
+	StopOnSwitch bool      `protobuf:"varint,4,opt,name=stop_on_switch,json=stopOnSwitch,proto3" json:"stop_on_switch,omitempty"`
+}
+
+func (x *HomingMove) Reset() {
+	*x = HomingMove{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HomingMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HomingMove) ProtoMessage() {}
+
+func (x *HomingMove) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HomingMove.ProtoReflect.Descriptor instead.
+func (*HomingMove) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HomingMove) GetMotors() []int32 {
+	if x != nil {
+		return x.Motors
+	}
+	return nil
+}
+
+func (x *HomingMove) GetDistances() []float64 {
+	if x != nil {
+		return x.Distances
+	}
+	return nil
+}
+
+func (x *HomingMove) GetVelocity() float64 {
+	if x != nil {
+		return x.Velocity
+	}
+	return 0
+}
+
+func (x *HomingMove) GetStopOnSwitch() bool {
+	if x != nil {
+		return x.StopOnSwitch
+	}
+	return false
+}
+
+// Result of a homing move, for each motor of the message
+type HomingMoveResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Positions []float64 `protobuf:"fixed64,1,rep,packed,name=positions,proto3" json:"positions,omitempty"`
+	Triggered []bool    `protobuf:"varint,2,rep,packed,name=triggered,proto3" json:"triggered,omitempty"`
+}
+
+func (x *HomingMoveResult) Reset() {
+	*x = HomingMoveResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HomingMoveResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HomingMoveResult) ProtoMessage() {}
+
+func (x *HomingMoveResult) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HomingMoveResult.ProtoReflect.Descriptor instead.
+func (*HomingMoveResult) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HomingMoveResult) GetPositions() []float64 {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+func (x *HomingMoveResult) GetTriggered() []bool {
+	if x != nil {
+		return x.Triggered
+	}
+	return nil
+}
+
+// Set the position of motors once they are homed
+type SetPosition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Motors    []int32   `protobuf:"varint,1,rep,packed,name=motors,proto3" json:"motors,omitempty"`
+	Positions []float64 `protobuf:"fixed64,2,rep,packed,name=positions,proto3" json:"positions,omitempty"`
+}
+
+func (x *SetPosition) Reset() {
+	*x = SetPosition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPosition) ProtoMessage() {}
+
+func (x *SetPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPosition.ProtoReflect.Descriptor instead.
+func (*SetPosition) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetPosition) GetMotors() []int32 {
+	if x != nil {
+		return x.Motors
+	}
+	return nil
+}
+
+func (x *SetPosition) GetPositions() []float64 {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+// Move the tool in a straight line
+type Move struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start    *Vector3D `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Target   *Vector3D `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Velocity float64   `protobuf:"fixed64,3,opt,name=velocity,proto3" json:"velocity,omitempty"`
+}
+
+func (x *Move) Reset() {
+	*x = Move{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Move) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Move) ProtoMessage() {}
+
+func (x *Move) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Move.ProtoReflect.Descriptor instead.
+func (*Move) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Move) GetStart() *Vector3D {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Move) GetTarget() *Vector3D {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *Move) GetVelocity() float64 {
+	if x != nil {
+		return x.Velocity
+	}
+	return 0
+}
+
+// Move the tool in a straight line until the probe triggers
+type ProbeMove struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start    *Vector3D `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Target   *Vector3D `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Velocity float64   `protobuf:"fixed64,3,opt,name=velocity,proto3" json:"velocity,omitempty"`
+	Toward   bool      `protobuf:"varint,4,opt,name=toward,proto3" json:"toward,omitempty"`
+}
+
+func (x *ProbeMove) Reset() {
+	*x = ProbeMove{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProbeMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProbeMove) ProtoMessage() {}
+
+func (x *ProbeMove) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProbeMove.ProtoReflect.Descriptor instead.
+func (*ProbeMove) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProbeMove) GetStart() *Vector3D {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *ProbeMove) GetTarget() *Vector3D {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *ProbeMove) GetVelocity() float64 {
+	if x != nil {
+		return x.Velocity
+	}
+	return 0
+}
+
+func (x *ProbeMove) GetToward() bool {
+	if x != nil {
+		return x.Toward
+	}
+	return false
+}
+
+// Trigger event of a probe move, the position is where the tool stopped
+type ProbeMoveResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Position  *Vector3D `protobuf:"bytes,1,opt,name=position,proto3" json:"position,omitempty"`
+	Triggered bool      `protobuf:"varint,2,opt,name=triggered,proto3" json:"triggered,omitempty"`
+}
+
+func (x *ProbeMoveResult) Reset() {
+	*x = ProbeMoveResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProbeMoveResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProbeMoveResult) ProtoMessage() {}
+
+func (x *ProbeMoveResult) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProbeMoveResult.ProtoReflect.Descriptor instead.
+func (*ProbeMoveResult) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ProbeMoveResult) GetPosition() *Vector3D {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *ProbeMoveResult) GetTriggered() bool {
+	if x != nil {
+		return x.Triggered
+	}
+	return false
+}
+
+// Read the spindle encoder
+type ReadEncoder struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReadEncoder) Reset() {
+	*x = ReadEncoder{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadEncoder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadEncoder) ProtoMessage() {}
+
+func (x *ReadEncoder) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadEncoder.ProtoReflect.Descriptor instead.
+func (*ReadEncoder) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{8}
+}
+
+// Spindle encoder feedback, in revolutions and revolutions per second
+type EncoderFeedback struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Position float64 `protobuf:"fixed64,1,opt,name=position,proto3" json:"position,omitempty"`
+	Velocity float64 `protobuf:"fixed64,2,opt,name=velocity,proto3" json:"velocity,omitempty"`
+}
+
+func (x *EncoderFeedback) Reset() {
+	*x = EncoderFeedback{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EncoderFeedback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EncoderFeedback) ProtoMessage() {}
+
+func (x *EncoderFeedback) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EncoderFeedback.ProtoReflect.Descriptor instead.
+func (*EncoderFeedback) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *EncoderFeedback) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *EncoderFeedback) GetVelocity() float64 {
+	if x != nil {
+		return x.Velocity
+	}
+	return 0
+}
+
+// Read the arc voltage of the plasma torch
+type ReadArcVoltage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReadArcVoltage) Reset() {
+	*x = ReadArcVoltage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReadArcVoltage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadArcVoltage) ProtoMessage() {}
+
+func (x *ReadArcVoltage) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadArcVoltage.ProtoReflect.Descriptor instead.
+func (*ReadArcVoltage) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{10}
+}
+
+// Arc voltage of the plasma torch, the arc is ok once transferred to the plate
+type ArcVoltage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Voltage float64 `protobuf:"fixed64,1,opt,name=voltage,proto3" json:"voltage,omitempty"`
+	ArcOk   bool    `protobuf:"varint,2,opt,name=arc_ok,json=arcOk,proto3" json:"arc_ok,omitempty"`
+}
+
+func (x *ArcVoltage) Reset() {
+	*x = ArcVoltage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ArcVoltage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArcVoltage) ProtoMessage() {}
+
+func (x *ArcVoltage) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArcVoltage.ProtoReflect.Descriptor instead.
+func (*ArcVoltage) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ArcVoltage) GetVoltage() float64 {
+	if x != nil {
+		return x.Voltage
+	}
+	return 0
+}
+
+func (x *ArcVoltage) GetArcOk() bool {
+	if x != nil {
+		return x.ArcOk
+	}
+	return false
+}
+
+// Request sent to the machine controller (MCU)
+type Request struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Request:
+	//	*Request_HomingMove
+	//	*Request_SetPosition
+	//	*Request_Move
+	//	*Request_ProbeMove
+	//	*Request_ReadEncoder
+	//	*Request_ReadArcVoltage
+	Request isRequest_Request `protobuf_oneof:"request"`
+}
+
+func (x *Request) Reset() {
+	*x = Request{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Request) ProtoMessage() {}
+
+func (x *Request) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Request.ProtoReflect.Descriptor instead.
+func (*Request) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{12}
+}
+
+func (m *Request) GetRequest() isRequest_Request {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (x *Request) GetHomingMove() *HomingMove {
+	if x, ok := x.GetRequest().(*Request_HomingMove); ok {
+		return x.HomingMove
+	}
+	return nil
+}
+
+func (x *Request) GetSetPosition() *SetPosition {
+	if x, ok := x.GetRequest().(*Request_SetPosition); ok {
+		return x.SetPosition
+	}
+	return nil
+}
+
+func (x *Request) GetMove() *Move {
+	if x, ok := x.GetRequest().(*Request_Move); ok {
+		return x.Move
+	}
+	return nil
+}
+
+func (x *Request) GetProbeMove() *ProbeMove {
+	if x, ok := x.GetRequest().(*Request_ProbeMove); ok {
+		return x.ProbeMove
+	}
+	return nil
+}
+
+func (x *Request) GetReadEncoder() *ReadEncoder {
+	if x, ok := x.GetRequest().(*Request_ReadEncoder); ok {
+		return x.ReadEncoder
+	}
+	return nil
+}
+
+func (x *Request) GetReadArcVoltage() *ReadArcVoltage {
+	if x, ok := x.GetRequest().(*Request_ReadArcVoltage); ok {
+		return x.ReadArcVoltage
+	}
+	return nil
+}
+
+type isRequest_Request interface {
+	isRequest_Request()
+}
+
+type Request_HomingMove struct {
+	HomingMove *HomingMove `protobuf:"bytes,1,opt,name=homing_move,json=homingMove,proto3,oneof"`
+}
+
+type Request_SetPosition struct {
+	SetPosition *SetPosition `protobuf:"bytes,2,opt,name=set_position,json=setPosition,proto3,oneof"`
+}
+
+type Request_Move struct {
+	Move *Move `protobuf:"bytes,3,opt,name=move,proto3,oneof"`
+}
+
+type Request_ProbeMove struct {
+	ProbeMove *ProbeMove `protobuf:"bytes,4,opt,name=probe_move,json=probeMove,proto3,oneof"`
+}
+
+type Request_ReadEncoder struct {
+	ReadEncoder *ReadEncoder `protobuf:"bytes,5,opt,name=read_encoder,json=readEncoder,proto3,oneof"`
+}
+
+type Request_ReadArcVoltage struct {
+	ReadArcVoltage *ReadArcVoltage `protobuf:"bytes,6,opt,name=read_arc_voltage,json=readArcVoltage,proto3,oneof"`
+}
+
+func (*Request_HomingMove) isRequest_Request() {}
+
+func (*Request_SetPosition) isRequest_Request() {}
+
+func (*Request_Move) isRequest_Request() {}
+
+func (*Request_ProbeMove) isRequest_Request() {}
+
+func (*Request_ReadEncoder) isRequest_Request() {}
+
+func (*Request_ReadArcVoltage) isRequest_Request() {}
+
+// Response of the MCU to a request, empty for the requests without a result
+type Response struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Set when the request failed
+	Error string `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	// Types that are assignable to Result:
+	//	*Response_HomingMoveResult
+	//	*Response_ProbeMoveResult
+	//	*Response_EncoderFeedback
+	//	*Response_ArcVoltage
+	Result isResponse_Result `protobuf_oneof:"result"`
+}
+
+func (x *Response) Reset() {
+	*x = Response{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_message_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Response) ProtoMessage() {}
+
+func (x *Response) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Response.ProtoReflect.Descriptor instead.
+func (*Response) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Response) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (m *Response) GetResult() isResponse_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (x *Response) GetHomingMoveResult() *HomingMoveResult {
+	if x, ok := x.GetResult().(*Response_HomingMoveResult); ok {
+		return x.HomingMoveResult
+	}
+	return nil
+}
+
+func (x *Response) GetProbeMoveResult() *ProbeMoveResult {
+	if x, ok := x.GetResult().(*Response_ProbeMoveResult); ok {
+		return x.ProbeMoveResult
+	}
+	return nil
+}
+
+func (x *Response) GetEncoderFeedback() *EncoderFeedback {
+	if x, ok := x.GetResult().(*Response_EncoderFeedback); ok {
+		return x.EncoderFeedback
+	}
+	return nil
+}
+
+func (x *Response) GetArcVoltage() *ArcVoltage {
+	if x, ok := x.GetResult().(*Response_ArcVoltage); ok {
+		return x.ArcVoltage
+	}
+	return nil
+}
+
+type isResponse_Result interface {
+	isResponse_Result()
+}
+
+type Response_HomingMoveResult struct {
+	HomingMoveResult *HomingMoveResult `protobuf:"bytes,2,opt,name=homing_move_result,json=homingMoveResult,proto3,oneof"`
+}
+
+type Response_ProbeMoveResult struct {
+	ProbeMoveResult *ProbeMoveResult `protobuf:"bytes,3,opt,name=probe_move_result,json=probeMoveResult,proto3,oneof"`
+}
+
+type Response_EncoderFeedback struct {
+	EncoderFeedback *EncoderFeedback `protobuf:"bytes,4,opt,name=encoder_feedback,json=encoderFeedback,proto3,oneof"`
+}
+
+type Response_ArcVoltage struct {
+	ArcVoltage *ArcVoltage `protobuf:"bytes,5,opt,name=arc_voltage,json=arcVoltage,proto3,oneof"`
+}
+
+func (*Response_HomingMoveResult) isResponse_Result() {}
+
+func (*Response_ProbeMoveResult) isResponse_Result() {}
+
+func (*Response_EncoderFeedback) isResponse_Result() {}
+
+func (*Response_ArcVoltage) isResponse_Result() {}
+
 var File_message_proto protoreflect.FileDescriptor
 
 var file_message_proto_rawDesc = []byte{
@@ -82,9 +966,103 @@ var file_message_proto_rawDesc = []byte{
 	0x04, 0x6d, 0x61, 0x69, 0x6e, 0x22, 0x2e, 0x0a, 0x06, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x12,
 	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
 	0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x03, 0x61, 0x67, 0x65, 0x42, 0x18, 0x5a, 0x16, 0x6c, 0x65, 0x6d, 0x77, 0x69, 0x6c, 0x6c,
-	0x2f, 0x67, 0x6f, 0x43, 0x4e, 0x43, 0x2d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x03, 0x61, 0x67, 0x65, 0x22, 0x34, 0x0a, 0x08, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x33,
+	0x64, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x78, 0x12,
+	0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x79, 0x12, 0x0c, 0x0a,
+	0x01, 0x7a, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x7a, 0x22, 0x84, 0x01, 0x0a, 0x0a,
+	0x48, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x4d, 0x6f, 0x76, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x6f,
+	0x74, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x05, 0x52, 0x06, 0x6d, 0x6f, 0x74, 0x6f,
+	0x72, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x09, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79, 0x12, 0x24, 0x0a, 0x0e,
+	0x73, 0x74, 0x6f, 0x70, 0x5f, 0x6f, 0x6e, 0x5f, 0x73, 0x77, 0x69, 0x74, 0x63, 0x68, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x73, 0x74, 0x6f, 0x70, 0x4f, 0x6e, 0x53, 0x77, 0x69, 0x74,
+	0x63, 0x68, 0x22, 0x4e, 0x0a, 0x10, 0x48, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x4d, 0x6f, 0x76, 0x65,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x01, 0x52, 0x09, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72,
+	0x65, 0x64, 0x22, 0x43, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x6f, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x05, 0x52, 0x06, 0x6d, 0x6f, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x09, 0x70, 0x6f,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x70, 0x0a, 0x04, 0x4d, 0x6f, 0x76, 0x65, 0x12,
+	0x24, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x33, 0x64, 0x52, 0x05,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x26, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x56, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x33, 0x64, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x1a, 0x0a,
+	0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79, 0x22, 0x8d, 0x01, 0x0a, 0x09, 0x50, 0x72,
+	0x6f, 0x62, 0x65, 0x4d, 0x6f, 0x76, 0x65, 0x12, 0x24, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x56, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x33, 0x64, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x26, 0x0a,
+	0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x33, 0x64, 0x52, 0x06, 0x74,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74,
+	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74,
+	0x79, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x6f, 0x77, 0x61, 0x72, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x74, 0x6f, 0x77, 0x61, 0x72, 0x64, 0x22, 0x5b, 0x0a, 0x0f, 0x50, 0x72, 0x6f,
+	0x62, 0x65, 0x4d, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2a, 0x0a, 0x08,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x56, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x33, 0x64, 0x52, 0x08,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x69, 0x67,
+	0x67, 0x65, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x69,
+	0x67, 0x67, 0x65, 0x72, 0x65, 0x64, 0x22, 0x0d, 0x0a, 0x0b, 0x52, 0x65, 0x61, 0x64, 0x45, 0x6e,
+	0x63, 0x6f, 0x64, 0x65, 0x72, 0x22, 0x49, 0x0a, 0x0f, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x72,
+	0x46, 0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79,
+	0x22, 0x10, 0x0a, 0x0e, 0x52, 0x65, 0x61, 0x64, 0x41, 0x72, 0x63, 0x56, 0x6f, 0x6c, 0x74, 0x61,
+	0x67, 0x65, 0x22, 0x3d, 0x0a, 0x0a, 0x41, 0x72, 0x63, 0x56, 0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x76, 0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x07, 0x76, 0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x61, 0x72,
+	0x63, 0x5f, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x61, 0x72, 0x63, 0x4f,
+	0x6b, 0x22, 0xcf, 0x02, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x33, 0x0a,
+	0x0b, 0x68, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x5f, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x48, 0x6f, 0x6d, 0x69, 0x6e, 0x67,
+	0x4d, 0x6f, 0x76, 0x65, 0x48, 0x00, 0x52, 0x0a, 0x68, 0x6f, 0x6d, 0x69, 0x6e, 0x67, 0x4d, 0x6f,
+	0x76, 0x65, 0x12, 0x36, 0x0a, 0x0c, 0x73, 0x65, 0x74, 0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e,
+	0x53, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0b, 0x73,
+	0x65, 0x74, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x04, 0x6d, 0x6f,
+	0x76, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e,
+	0x4d, 0x6f, 0x76, 0x65, 0x48, 0x00, 0x52, 0x04, 0x6d, 0x6f, 0x76, 0x65, 0x12, 0x30, 0x0a, 0x0a,
+	0x70, 0x72, 0x6f, 0x62, 0x65, 0x5f, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0f, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x65, 0x4d, 0x6f, 0x76,
+	0x65, 0x48, 0x00, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x4d, 0x6f, 0x76, 0x65, 0x12, 0x36,
+	0x0a, 0x0c, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x52, 0x65, 0x61, 0x64,
+	0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x48, 0x00, 0x52, 0x0b, 0x72, 0x65, 0x61, 0x64, 0x45,
+	0x6e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x12, 0x40, 0x0a, 0x10, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x61,
+	0x72, 0x63, 0x5f, 0x76, 0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x41, 0x72, 0x63, 0x56,
+	0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x0e, 0x72, 0x65, 0x61, 0x64, 0x41, 0x72,
+	0x63, 0x56, 0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x22, 0xb0, 0x02, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x46, 0x0a, 0x12, 0x68, 0x6f, 0x6d, 0x69, 0x6e, 0x67,
+	0x5f, 0x6d, 0x6f, 0x76, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x48, 0x6f, 0x6d, 0x69, 0x6e, 0x67,
+	0x4d, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x48, 0x00, 0x52, 0x10, 0x68, 0x6f,
+	0x6d, 0x69, 0x6e, 0x67, 0x4d, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x43,
+	0x0a, 0x11, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x5f, 0x6d, 0x6f, 0x76, 0x65, 0x5f, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x61, 0x69, 0x6e,
+	0x2e, 0x50, 0x72, 0x6f, 0x62, 0x65, 0x4d, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x48, 0x00, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x4d, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x42, 0x0a, 0x10, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x5f, 0x66,
+	0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x6d, 0x61, 0x69, 0x6e, 0x2e, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x46, 0x65, 0x65, 0x64,
+	0x62, 0x61, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x0f, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x46,
+	0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x33, 0x0a, 0x0b, 0x61, 0x72, 0x63, 0x5f, 0x76,
+	0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d,
+	0x61, 0x69, 0x6e, 0x2e, 0x41, 0x72, 0x63, 0x56, 0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x48, 0x00,
+	0x52, 0x0a, 0x61, 0x72, 0x63, 0x56, 0x6f, 0x6c, 0x74, 0x61, 0x67, 0x65, 0x42, 0x08, 0x0a, 0x06,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x18, 0x5a, 0x16, 0x6c, 0x65, 0x6d, 0x77, 0x69, 0x6c,
+	0x6c, 0x2f, 0x67, 0x6f, 0x43, 0x4e, 0x43, 0x2d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -99,16 +1077,44 @@ func file_message_proto_rawDescGZIP() []byte {
 	return file_message_proto_rawDescData
 }
 
-var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_message_proto_goTypes = []interface{}{
-	(*Person)(nil), // 0: main.Person
+	(*Person)(nil),           // 0: main.Person
+	(*Vector3D)(nil),         // 1: main.Vector3d
+	(*HomingMove)(nil),       // 2: main.HomingMove
+	(*HomingMoveResult)(nil), // 3: main.HomingMoveResult
+	(*SetPosition)(nil),      // 4: main.SetPosition
+	(*Move)(nil),             // 5: main.Move
+	(*ProbeMove)(nil),        // 6: main.ProbeMove
+	(*ProbeMoveResult)(nil),  // 7: main.ProbeMoveResult
+	(*ReadEncoder)(nil),      // 8: main.ReadEncoder
+	(*EncoderFeedback)(nil),  // 9: main.EncoderFeedback
+	(*ReadArcVoltage)(nil),   // 10: main.ReadArcVoltage
+	(*ArcVoltage)(nil),       // 11: main.ArcVoltage
+	(*Request)(nil),          // 12: main.Request
+	(*Response)(nil),         // 13: main.Response
 }
 var file_message_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: main.Move.start:type_name -> main.Vector3d
+	1,  // 1: main.Move.target:type_name -> main.Vector3d
+	1,  // 2: main.ProbeMove.start:type_name -> main.Vector3d
+	1,  // 3: main.ProbeMove.target:type_name -> main.Vector3d
+	1,  // 4: main.ProbeMoveResult.position:type_name -> main.Vector3d
+	2,  // 5: main.Request.homing_move:type_name -> main.HomingMove
+	4,  // 6: main.Request.set_position:type_name -> main.SetPosition
+	5,  // 7: main.Request.move:type_name -> main.Move
+	6,  // 8: main.Request.probe_move:type_name -> main.ProbeMove
+	8,  // 9: main.Request.read_encoder:type_name -> main.ReadEncoder
+	10, // 10: main.Request.read_arc_voltage:type_name -> main.ReadArcVoltage
+	3,  // 11: main.Response.homing_move_result:type_name -> main.HomingMoveResult
+	7,  // 12: main.Response.probe_move_result:type_name -> main.ProbeMoveResult
+	9,  // 13: main.Response.encoder_feedback:type_name -> main.EncoderFeedback
+	11, // 14: main.Response.arc_voltage:type_name -> main.ArcVoltage
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_message_proto_init() }
@@ -129,6 +1135,176 @@ func file_message_proto_init() {
 				return nil
 			}
 		}
+		file_message_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Vector3D); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HomingMove); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HomingMoveResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetPosition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Move); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProbeMove); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProbeMoveResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadEncoder); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EncoderFeedback); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReadArcVoltage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ArcVoltage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Request); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_message_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Response); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_message_proto_msgTypes[12].OneofWrappers = []interface{}{
+		(*Request_HomingMove)(nil),
+		(*Request_SetPosition)(nil),
+		(*Request_Move)(nil),
+		(*Request_ProbeMove)(nil),
+		(*Request_ReadEncoder)(nil),
+		(*Request_ReadArcVoltage)(nil),
+	}
+	file_message_proto_msgTypes[13].OneofWrappers = []interface{}{
+		(*Response_HomingMoveResult)(nil),
+		(*Response_ProbeMoveResult)(nil),
+		(*Response_EncoderFeedback)(nil),
+		(*Response_ArcVoltage)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -136,7 +1312,7 @@ func file_message_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_message_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Height map interpolation enum (HeightMapBilinear, HeightMapBicubic)
+type HeightMapInterpolation int
+
+const (
+	HeightMapBilinear HeightMapInterpolation = iota
+	HeightMapBicubic
+)
+
+// Grid of surface heights probed over a rectangle of the XY plane. The heights
+// are interpolated between the points and kept constant past the edges.
+type HeightMap struct {
+	min           Vector3d
+	max           Vector3d
+	columns       int
+	rows          int
+	heights       [][]float64
+	interpolation HeightMapInterpolation
+}
+
+// Create a new flat height map with columns along X and rows along Y, the
+// rectangle is given by its XY corners
+func newHeightMap(min Vector3d, max Vector3d, columns int, rows int) *HeightMap {
+	heights := make([][]float64, rows)
+	for row := range heights {
+		heights[row] = make([]float64, columns)
+	}
+	return &HeightMap{min: min, max: max, columns: columns, rows: rows, heights: heights}
+}
+
+// Set the interpolation between the points
+func (h *HeightMap) setInterpolation(interpolation HeightMapInterpolation) {
+	h.interpolation = interpolation
+}
+
+// Get the XY position of a point of the grid
+func (h *HeightMap) getPoint(column int, row int) Vector3d {
+	point := Vector3d{X: h.min.X, Y: h.min.Y, Z: 0}
+	if h.columns > 1 {
+		point.X += (h.max.X - h.min.X) * float64(column) / float64(h.columns-1)
+	}
+	if h.rows > 1 {
+		point.Y += (h.max.Y - h.min.Y) * float64(row) / float64(h.rows-1)
+	}
+	return point
+}
+
+// Set the height of a point of the grid
+func (h *HeightMap) setHeight(column int, row int, height float64) {
+	h.heights[row][column] = height
+}
+
+// Get the height of a point of the grid, the points past the edges take the height of the edge
+func (h *HeightMap) getGridHeight(column int, row int) float64 {
+	column = int(math.Max(0, math.Min(float64(column), float64(h.columns-1))))
+	row = int(math.Max(0, math.Min(float64(row), float64(h.rows-1))))
+	return h.heights[row][column]
+}
+
+// Get the position of a coordinate in grid units, clamped to the grid
+func gridCoordinate(value float64, min float64, max float64, count int) float64 {
+	if count < 2 || max == min {
+		return 0
+	}
+	coordinate := (value - min) / (max - min) * float64(count-1)
+	return math.Max(0, math.Min(coordinate, float64(count-1)))
+}
+
+// Get the interpolated height at an XY position
+func (h *HeightMap) getHeight(x float64, y float64) float64 {
+	if h.columns == 0 || h.rows == 0 {
+		return 0
+	}
+
+	u := gridCoordinate(x, h.min.X, h.max.X, h.columns)
+	v := gridCoordinate(y, h.min.Y, h.max.Y, h.rows)
+	column := int(math.Floor(u))
+	row := int(math.Floor(v))
+	u -= float64(column)
+	v -= float64(row)
+
+	if h.interpolation == HeightMapBicubic {
+		// Catmull-Rom through the 4 by 4 points around the position
+		var heights [4]float64
+		for i := 0; i < 4; i++ {
+			r := row + i - 1
+			heights[i] = cubicInterpolate(h.getGridHeight(column-1, r), h.getGridHeight(column, r), h.getGridHeight(column+1, r), h.getGridHeight(column+2, r), u)
+		}
+		return cubicInterpolate(heights[0], heights[1], heights[2], heights[3], v)
+	}
+
+	bottom := h.getGridHeight(column, row) + u*(h.getGridHeight(column+1, row)-h.getGridHeight(column, row))
+	top := h.getGridHeight(column, row+1) + u*(h.getGridHeight(column+1, row+1)-h.getGridHeight(column, row+1))
+	return bottom + v*(top-bottom)
+}
+
+// Interpolate between p1 and p2 with a Catmull-Rom spline
+func cubicInterpolate(p0 float64, p1 float64, p2 float64, p3 float64, t float64) float64 {
+	return p1 + 0.5*t*(p2-p0+t*(2*p0-5*p1+4*p2-p3+t*(3*(p1-p2)+p3-p0)))
+}
+
+// Move a position to follow the surface
+func (h *HeightMap) level(position Vector3d) Vector3d {
+	position.Z += h.getHeight(position.X, position.Y)
+	return position
+}
+
+// Save the height map to a file. The first line holds the corners and the
+// size of the grid, the next lines the heights of each row.
+func (h *HeightMap) save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	fmt.Fprintf(writer, "%g %g %g %g %d %d\n", h.min.X, h.min.Y, h.max.X, h.max.Y, h.columns, h.rows)
+	for _, row := range h.heights {
+		values := make([]string, len(row))
+		for i, height := range row {
+			values[i] = strconv.FormatFloat(height, 'g', -1, 64)
+		}
+		fmt.Fprintln(writer, strings.Join(values, " "))
+	}
+
+	// The file is only complete once closed
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load a height map saved to a file
+func loadHeightMap(filename string) (*HeightMap, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("%s: missing height map header", filename)
+	}
+
+	var min, max Vector3d
+	var columns, rows int
+	if _, err := fmt.Sscanf(scanner.Text(), "%g %g %g %g %d %d", &min.X, &min.Y, &max.X, &max.Y, &columns, &rows); err != nil {
+		return nil, fmt.Errorf("%s: invalid height map header: %v", filename, err)
+	}
+	if columns < 1 || rows < 1 {
+		return nil, fmt.Errorf("%s: invalid height map size %d x %d", filename, columns, rows)
+	}
+
+	heightMap := newHeightMap(min, max, columns, rows)
+	for row := 0; row < rows; row++ {
+		if !scanner.Scan() {
+			return nil, fmt.Errorf("%s: missing row %d", filename, row)
+		}
+		values := strings.Fields(scanner.Text())
+		if len(values) != columns {
+			return nil, fmt.Errorf("%s: row %d has %d heights instead of %d", filename, row, len(values), columns)
+		}
+		for column, value := range values {
+			height, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid height: %s", filename, value)
+			}
+			heightMap.setHeight(column, row, height)
+		}
+	}
+
+	return heightMap, scanner.Err()
+}
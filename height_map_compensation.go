@@ -0,0 +1,50 @@
+package main
+
+import "math"
+
+// Set the height map the movements follow. Movements are split in segments
+// no longer than segmentLength so they follow the surface between the points
+// of the map.
+func (m *MotionPlanner) setHeightMap(heightMap *HeightMap, segmentLength float64) {
+	m.heightMap = heightMap
+	m.heightMapSegmentLength = segmentLength
+}
+
+// Offset the Z of the movements by the height of the surface under them. Arcs
+// are split in arcs around the same center, which become helixes between the
+// heights at their ends.
+func (m *MotionPlanner) levelMovements() {
+	var arr []interface{}
+	var previous *Vector3d
+
+	for _, command := range m.commandList.arr {
+		movement, ok := command.(Movement)
+		if !ok {
+			arr = append(arr, command)
+			continue
+		}
+
+		start := m.heightMap.level(movement.getStartPosition())
+		if previous != nil {
+			start = *previous
+		}
+
+		segments := 1
+		if m.heightMapSegmentLength > 0 {
+			segments = int(math.Max(1, math.Ceil(movement.getLength()/m.heightMapSegmentLength)))
+		}
+
+		for i := 1; i <= segments; i++ {
+			segment := movement.getSegment(float64(i-1)/float64(segments), float64(i)/float64(segments))
+			segment.setStartPosition(start)
+			segment.setEndPosition(m.heightMap.level(segment.getEndPosition()))
+
+			arr = append(arr, segment)
+			start = segment.getEndPosition()
+		}
+
+		previous = &start
+	}
+
+	m.commandList.arr = arr
+}
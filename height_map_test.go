@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Create a height map over 0..size in X and Y with the heights of a function at each point
+func newTestHeightMap(size float64, points int, height func(x float64, y float64) float64) *HeightMap {
+	heightMap := newHeightMap(Vector3d{}, Vector3d{X: size, Y: size}, points, points)
+	for row := 0; row < points; row++ {
+		for column := 0; column < points; column++ {
+			point := heightMap.getPoint(column, row)
+			heightMap.setHeight(column, row, height(point.X, point.Y))
+		}
+	}
+	return heightMap
+}
+
+func TestHeightMapBilinear(t *testing.T) {
+	heightMap := newTestHeightMap(10, 2, func(x float64, y float64) float64 { return x/10 + 2*y/10 })
+
+	checkFloat(t, "corner", heightMap.getHeight(10, 10), 3)
+	checkFloat(t, "center", heightMap.getHeight(5, 5), 1.5)
+	checkFloat(t, "inside", heightMap.getHeight(2.5, 7.5), 1.75)
+	// Past the edges the height of the edge is kept
+	checkFloat(t, "past the edges", heightMap.getHeight(20, -5), 1)
+	checkVector(t, "leveled position", heightMap.level(Vector3d{X: 5, Y: 5, Z: -1}), Vector3d{X: 5, Y: 5, Z: 0.5})
+}
+
+func TestHeightMapBicubic(t *testing.T) {
+	heightMap := newTestHeightMap(3, 4, func(x float64, y float64) float64 { return x*x + y })
+	heightMap.setInterpolation(HeightMapBicubic)
+
+	checkFloat(t, "grid point", heightMap.getHeight(2, 1), 5)
+	// The spline follows the curve of the points around the position where
+	// the bilinear interpolation would cut across
+	checkFloat(t, "between the points", heightMap.getHeight(1.5, 1.5), 3.75)
+	checkFloat(t, "past the edges", heightMap.getHeight(3, 10), 12)
+}
+
+func TestHeightMapSaveLoad(t *testing.T) {
+	heightMap := newHeightMap(Vector3d{X: -5, Y: 2}, Vector3d{X: 15, Y: 12}, 3, 2)
+	heightMap.setHeight(0, 0, 0.125)
+	heightMap.setHeight(2, 0, -0.5)
+	heightMap.setHeight(1, 1, 1e-7)
+
+	filename := filepath.Join(t.TempDir(), "height.map")
+	if err := heightMap.save(filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadHeightMap(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkVector(t, "min", loaded.min, heightMap.min)
+	checkVector(t, "max", loaded.max, heightMap.max)
+	if loaded.columns != 3 || loaded.rows != 2 {
+		t.Fatalf("got %d x %d points, expected 3 x 2", loaded.columns, loaded.rows)
+	}
+	for row := 0; row < 2; row++ {
+		for column := 0; column < 3; column++ {
+			if loaded.heights[row][column] != heightMap.heights[row][column] {
+				t.Errorf("point %d, %d: got %g, expected %g", column, row, loaded.heights[row][column], heightMap.heights[row][column])
+			}
+		}
+	}
+}
+
+func TestHeightMapLoadErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty":          "",
+		"invalid header": "0 0 10\n",
+		"invalid size":   "0 0 10 10 0 2\n",
+		"missing row":    "0 0 10 10 2 2\n0 1\n",
+		"short row":      "0 0 10 10 2 2\n0 1\n2\n",
+		"invalid height": "0 0 10 10 2 2\n0 1\n2 x\n",
+	} {
+		filename := filepath.Join(t.TempDir(), "height.map")
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadHeightMap(filename); err == nil {
+			t.Errorf("%s: loaded the height map", name)
+		}
+	}
+
+	if _, err := loadHeightMap(filepath.Join(t.TempDir(), "missing.map")); err == nil {
+		t.Errorf("loaded a missing file")
+	}
+}
+
+func TestLevelLinearMovements(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G1 X10 F600", "G0 Y10"})
+	planner.setHeightMap(newTestHeightMap(10, 2, func(x float64, y float64) float64 { return x / 10 }), 5)
+	planner.levelMovements()
+
+	checkEndPositions(t, planner, []Vector3d{{X: 5, Z: 0.5}, {X: 10, Z: 1}, {X: 10, Y: 5, Z: 1}, {X: 10, Y: 10, Z: 1}})
+	movements := planner.commandList.GetMovementList()
+	checkFloat(t, "segment velocity", movements[1].getGcodeVelocity(), 10)
+	checkVector(t, "segment start", movements[1].getStartPosition(), Vector3d{X: 5, Z: 0.5})
+}
+
+func TestLevelArcs(t *testing.T) {
+	// Half a circle through X5 Y5, climbing the slope of the map along Y
+	planner := planLines(defaultMachineConfiguration(), []string{"G2 X10 I5 F600"})
+	planner.setHeightMap(newTestHeightMap(10, 2, func(x float64, y float64) float64 { return y / 10 }), 1)
+	planner.levelMovements()
+
+	movements := planner.commandList.GetMovementList()
+	if len(movements) != 16 {
+		t.Fatalf("got %d segments, expected 16", len(movements))
+	}
+	for i, movement := range movements {
+		arc, ok := movement.(*ArcMovement)
+		if !ok {
+			t.Fatalf("segment %d is %v, expected an arc", i, movement)
+		}
+		checkVector(t, "segment center", arc.getCenter().project(ZAxis), Vector3d{X: 5})
+		end := arc.getEndPosition()
+		checkFloat(t, "segment height", end.Z, end.Y/10)
+		checkFloat(t, "segment velocity", arc.getGcodeVelocity(), 10)
+	}
+	checkVector(t, "half way", movements[7].getEndPosition(), Vector3d{X: 5, Y: 5, Z: 0.5})
+	checkVector(t, "end", movements[15].getEndPosition(), Vector3d{X: 10})
+}
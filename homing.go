@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Home the axes in order. Each group of axes seeks its switches, pulls off,
+// latches slowly on the switches and pulls off again. Every motor stops on its
+// own switch, which squares the gantries driven by two motors.
+func homeAxes(link MachineLink, axes []*HomingAxis) error {
+	groups := make(map[int][]*HomingAxis)
+	var orders []int
+	for _, axis := range axes {
+		if _, ok := groups[axis.order]; !ok {
+			orders = append(orders, axis.order)
+		}
+		groups[axis.order] = append(groups[axis.order], axis)
+	}
+	sort.Ints(orders)
+
+	for _, order := range orders {
+		if err := homeGroup(link, groups[order]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Home a group of axes together
+func homeGroup(link MachineLink, axes []*HomingAxis) error {
+	// Seek the switches quickly
+	if err := homingStep(link, axes, func(axis *HomingAxis, index int) float64 { return axis.direction * axis.maxTravel }, seekVelocity(axes), true); err != nil {
+		return fmt.Errorf("homing seek: %w", err)
+	}
+
+	// Back off the switches
+	if err := homingStep(link, axes, func(axis *HomingAxis, index int) float64 { return -axis.direction * axis.pullOff }, latchVelocity(axes), false); err != nil {
+		return fmt.Errorf("homing pull-off: %w", err)
+	}
+
+	// Latch the switches slowly for a precise position
+	if err := homingStep(link, axes, func(axis *HomingAxis, index int) float64 { return axis.direction * 2 * axis.pullOff }, latchVelocity(axes), true); err != nil {
+		return fmt.Errorf("homing latch: %w", err)
+	}
+
+	// Back off the switches and square the gantries
+	if err := homingStep(link, axes, func(axis *HomingAxis, index int) float64 {
+		return -axis.direction * (axis.pullOff + axis.squaringOffsets[index])
+	}, latchVelocity(axes), false); err != nil {
+		return fmt.Errorf("homing pull-off: %w", err)
+	}
+
+	// The motors are now at the home position of their axis
+	message := SetPositionMessage{}
+	for _, axis := range axes {
+		for _, motor := range axis.motors {
+			message.motors = append(message.motors, motor)
+			message.positions = append(message.positions, axis.homePosition)
+		}
+	}
+
+	return link.setPosition(message)
+}
+
+// Move all the motors of the axes by the given distance, checking the
+// switches triggered if the move stops on them
+func homingStep(link MachineLink, axes []*HomingAxis, distance func(axis *HomingAxis, index int) float64, velocity float64, stopOnSwitch bool) error {
+	message := HomingMoveMessage{velocity: velocity, stopOnSwitch: stopOnSwitch}
+	for _, axis := range axes {
+		for i, motor := range axis.motors {
+			message.motors = append(message.motors, motor)
+			message.distances = append(message.distances, distance(axis, i))
+		}
+	}
+
+	result, err := link.homingMove(message)
+	if err != nil {
+		return err
+	}
+
+	if stopOnSwitch {
+		for i, triggered := range result.triggered {
+			if !triggered {
+				return fmt.Errorf("switch of motor %d not found", message.motors[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get the seek velocity of the slowest axis of the group
+func seekVelocity(axes []*HomingAxis) float64 {
+	velocity := axes[0].seekVelocity
+	for _, axis := range axes {
+		if axis.seekVelocity < velocity {
+			velocity = axis.seekVelocity
+		}
+	}
+	return velocity
+}
+
+// Get the latch velocity of the slowest axis of the group
+func latchVelocity(axes []*HomingAxis) float64 {
+	velocity := axes[0].latchVelocity
+	for _, axis := range axes {
+		if axis.latchVelocity < velocity {
+			velocity = axis.latchVelocity
+		}
+	}
+	return velocity
+}
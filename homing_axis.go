@@ -0,0 +1,51 @@
+package main
+
+// Homing configuration of an axis
+type HomingAxis struct {
+	axis Axis
+
+	// Axes with the same order are homed together, lowest first
+	order int
+
+	// Direction of the switch, -1 or 1
+	direction float64
+
+	seekVelocity  float64
+	latchVelocity float64
+
+	// Distance to back off the switch after seeking and latching
+	pullOff float64
+
+	// Position of the axis once pulled off the switch
+	homePosition float64
+
+	// Distance to search for the switch before failing
+	maxTravel float64
+
+	// Motors driving the axis, gantries have one per side
+	motors []int
+
+	// Distance each motor moves after the pull-off to square the gantry
+	squaringOffsets []float64
+}
+
+// Create a new homing axis configuration
+func newHomingAxis(axis Axis, order int, direction float64, seekVelocity float64, latchVelocity float64, pullOff float64, homePosition float64, maxTravel float64, motors []int) *HomingAxis {
+	return &HomingAxis{
+		axis:            axis,
+		order:           order,
+		direction:       direction,
+		seekVelocity:    seekVelocity,
+		latchVelocity:   latchVelocity,
+		pullOff:         pullOff,
+		homePosition:    homePosition,
+		maxTravel:       maxTravel,
+		motors:          motors,
+		squaringOffsets: make([]float64, len(motors)),
+	}
+}
+
+// Set the squaring offset of each motor of a gantry
+func (h *HomingAxis) setSquaringOffsets(squaringOffsets []float64) {
+	h.squaringOffsets = squaringOffsets
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Check that the switch of a motor is at a distance of its home position,
+// the simulator moves the switches with the coordinates set once homed
+func checkSwitchPosition(t *testing.T, simulator *McuSimulator, motor int, expected float64) {
+	t.Helper()
+	checkFloat(t, "motor position", simulator.getPosition(motor), 0)
+	checkFloat(t, "switch position", simulator.switchPositions[motor], expected)
+}
+
+func TestHomingAxis(t *testing.T) {
+	simulator := newMcuSimulator([]float64{120})
+	axis := newHomingAxis(XAxis, 0, 1, 50, 5, 2, 0, 300, []int{0})
+
+	if err := homeAxes(simulator, []*HomingAxis{axis}); err != nil {
+		t.Fatal(err)
+	}
+	// The axis is pulled off the switch and set to its home position
+	checkSwitchPosition(t, simulator, 0, 2)
+}
+
+func TestHomingSquaresGantry(t *testing.T) {
+	// The two sides of the gantry are skewed by 3 before homing
+	simulator := newMcuSimulator([]float64{-80, -83})
+	gantry := newHomingAxis(YAxis, 0, -1, 50, 5, 2, 0, 300, []int{0, 1})
+	gantry.setSquaringOffsets([]float64{0, 0.5})
+
+	if err := homeAxes(simulator, []*HomingAxis{gantry}); err != nil {
+		t.Fatal(err)
+	}
+	// Each side stops on its own switch, the second one moves further to
+	// correct the mounting of its switch
+	checkSwitchPosition(t, simulator, 0, -2)
+	checkSwitchPosition(t, simulator, 1, -2.5)
+}
+
+func TestHomingOrder(t *testing.T) {
+	// Z homes first, its switch is found before X moves
+	simulator := newMcuSimulator([]float64{50, 20})
+	x := newHomingAxis(XAxis, 1, 1, 50, 5, 1, 0, 100, []int{0})
+	z := newHomingAxis(ZAxis, 0, 1, 50, 5, 1, 0, 30, []int{1})
+
+	if err := homeAxes(simulator, []*HomingAxis{x, z}); err != nil {
+		t.Fatal(err)
+	}
+	checkSwitchPosition(t, simulator, 0, 1)
+	checkSwitchPosition(t, simulator, 1, 1)
+}
+
+func TestHomingSwitchNotFound(t *testing.T) {
+	simulator := newMcuSimulator([]float64{500})
+	configuration := defaultMachineConfiguration()
+	configuration.setHomingAxes([]*HomingAxis{newHomingAxis(XAxis, 0, 1, 50, 5, 2, 0, 300, []int{0})})
+	machine := newMachine(configuration, simulator)
+
+	err := machine.home()
+	if err == nil || !strings.Contains(err.Error(), "switch of motor 0 not found") {
+		t.Fatalf("got %v, expected the switch not to be found", err)
+	}
+	if machine.getState() != StateAlarm || machine.isHomed() {
+		t.Errorf("got %s, expected an alarm without being homed", machine.getState())
+	}
+}
+
+func TestMachineHome(t *testing.T) {
+	simulator := newMcuSimulator([]float64{120, 40})
+	configuration := defaultMachineConfiguration()
+	configuration.setHomingAxes([]*HomingAxis{
+		newHomingAxis(XAxis, 0, 1, 50, 5, 2, 200, 300, []int{0}),
+		newHomingAxis(ZAxis, 0, 1, 50, 5, 2, 10, 300, []int{1}),
+	})
+	machine := newMachine(configuration, simulator)
+	if machine.getState() != StateAlarm {
+		t.Fatalf("got %s, expected to start in alarm until homed", machine.getState())
+	}
+
+	if err := machine.home(); err != nil {
+		t.Fatal(err)
+	}
+	if machine.getState() != StateIdle || !machine.isHomed() {
+		t.Errorf("got %s, expected homed and idle", machine.getState())
+	}
+	checkVector(t, "machine position", machine.getPosition(), Vector3d{X: 200, Z: 10})
+}
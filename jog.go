@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Jog of the machine along a straight line with a trapezoidal velocity
+// profile. A cancelled jog decelerates to a stop at the maximum acceleration.
+type Jog struct {
+	start        Vector3d
+	direction    Vector3d
+	length       float64
+	velocity     float64
+	acceleration float64
+
+	cancelled      bool
+	cancelTime     float64
+	cancelDistance float64
+	cancelVelocity float64
+}
+
+// Create a new jog, the velocity is reduced if it cannot be reached over the length
+func newJog(start Vector3d, end Vector3d, velocity float64, acceleration float64) *Jog {
+	length := end.subtract(start).length()
+	direction := Vector3d{X: 0, Y: 0, Z: 0}
+	if length > 0 {
+		direction = end.subtract(start).normalize()
+	}
+
+	velocity = math.Min(velocity, math.Sqrt(acceleration*length))
+
+	return &Jog{start: start, direction: direction, length: length, velocity: velocity, acceleration: acceleration}
+}
+
+// Get the duration of the acceleration
+func (j *Jog) getAccelerationTime() float64 {
+	return j.velocity / j.acceleration
+}
+
+// Get the duration of the jog
+func (j *Jog) getDuration() float64 {
+	if j.length == 0 {
+		return 0
+	}
+	if j.cancelled {
+		return j.cancelTime + j.cancelVelocity/j.acceleration
+	}
+
+	accelerationDistance := j.velocity * j.velocity / (2 * j.acceleration)
+	return 2*j.getAccelerationTime() + (j.length-2*accelerationDistance)/j.velocity
+}
+
+// Get the distance travelled and the velocity at a time since the start
+func (j *Jog) getDistanceAt(time float64) (float64, float64) {
+	duration := j.getDuration()
+	if time >= duration {
+		return j.getLength(), 0
+	}
+
+	if j.cancelled && time > j.cancelTime {
+		elapsed := time - j.cancelTime
+		return j.cancelDistance + j.cancelVelocity*elapsed - j.acceleration*elapsed*elapsed/2, j.cancelVelocity - j.acceleration*elapsed
+	}
+
+	accelerationTime := j.getAccelerationTime()
+	if time < accelerationTime {
+		return j.acceleration * time * time / 2, j.acceleration * time
+	}
+
+	remaining := duration - time
+	if remaining < accelerationTime {
+		return j.length - j.acceleration*remaining*remaining/2, j.acceleration * remaining
+	}
+
+	return j.velocity*accelerationTime/2 + j.velocity*(time-accelerationTime), j.velocity
+}
+
+// Get the position at a time since the start
+func (j *Jog) getPositionAt(time float64) Vector3d {
+	distance, _ := j.getDistanceAt(time)
+	return j.start.Add(j.direction.scale(distance))
+}
+
+// Get the length of the jog, shortened if it was cancelled
+func (j *Jog) getLength() float64 {
+	if j.cancelled {
+		return j.cancelDistance + j.cancelVelocity*j.cancelVelocity/(2*j.acceleration)
+	}
+	return j.length
+}
+
+// Get the end position of the jog
+func (j *Jog) getEndPosition() Vector3d {
+	return j.start.Add(j.direction.scale(j.getLength()))
+}
+
+// Cancel the jog at a time since the start, it decelerates from there
+func (j *Jog) cancel(time float64) {
+	if j.cancelled || time >= j.getDuration() {
+		return
+	}
+
+	j.cancelDistance, j.cancelVelocity = j.getDistanceAt(time)
+	j.cancelTime = time
+	j.cancelled = true
+}
+
+// Return a string representation of the jog
+func (j *Jog) String() string {
+	return fmt.Sprintf("Jog:         Pos: %7.3f -> %7.3f  Velocity: %7.3f m/s", j.start, j.getEndPosition(), j.velocity)
+}
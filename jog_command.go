@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Millimeters per inch, jogs are in millimeters unless G20 is given
+const millimetersPerInch = 25.4
+
+// Jog command using the $J= syntax of the senders, such as $J=G91 X10 F500
+type JogCommand struct {
+	axes        map[string]float64
+	incremental bool
+	machine     bool
+	feedrate    float64
+}
+
+// Parse a $J= jog command
+func parseJogCommand(line string) (*JogCommand, error) {
+	if !strings.HasPrefix(line, "$J=") {
+		return nil, fmt.Errorf("not a jog command: %s", line)
+	}
+
+	command := &JogCommand{axes: make(map[string]float64)}
+	scale := 1.0
+	hasFeedrate := false
+
+	words, err := tokenizeLine(line[3:])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, word := range words {
+		letter := string(word.letter)
+		val := word.value
+
+		switch letter {
+		case "G":
+			switch word.String() {
+			case "G20":
+				scale = millimetersPerInch
+			case "G21":
+				scale = 1
+			case "G90":
+				command.incremental = false
+			case "G91":
+				command.incremental = true
+			case "G53":
+				command.machine = true
+			default:
+				return nil, fmt.Errorf("unsupported jog word: %s", word)
+			}
+		case "X", "Y", "Z":
+			command.axes[letter] = val
+		case "F":
+			command.feedrate = val
+			hasFeedrate = true
+		default:
+			return nil, fmt.Errorf("unsupported jog word: %s", word)
+		}
+	}
+
+	if !hasFeedrate || command.feedrate <= 0 {
+		return nil, errors.New("jog commands need a feed rate")
+	}
+	if len(command.axes) == 0 {
+		return nil, errors.New("jog commands need an axis")
+	}
+
+	for axis := range command.axes {
+		command.axes[axis] *= scale
+	}
+	command.feedrate *= scale
+
+	return command, nil
+}
+
+// Get the target machine position of the jog from the current machine position
+func (j *JogCommand) getTarget(position Vector3d, workOffset Vector3d) Vector3d {
+	target := position
+	values := map[string]*float64{"X": &target.X, "Y": &target.Y, "Z": &target.Z}
+	offsets := map[string]float64{"X": workOffset.X, "Y": workOffset.Y, "Z": workOffset.Z}
+
+	for axis, val := range j.axes {
+		if j.incremental {
+			*values[axis] += val
+		} else if j.machine {
+			*values[axis] = val
+		} else {
+			*values[axis] = val + offsets[axis]
+		}
+	}
+
+	return target
+}
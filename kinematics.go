@@ -0,0 +1,13 @@
+package main
+
+// Create an interface for the mapping between the cartesian axes and the motors
+type Kinematics interface {
+	// Get the motor positions for a cartesian position
+	inverse(position Vector3d) Vector3d
+	// Get the cartesian position for the motor positions
+	forward(motors Vector3d) Vector3d
+	// Get the maximum velocity of each motor
+	getMaxMotorVelocity() Vector3d
+	// Get the maximum acceleration of each motor
+	getMaxMotorAcceleration() Vector3d
+}
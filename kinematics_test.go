@@ -0,0 +1,81 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Check that the forward kinematics undoes the inverse kinematics
+func checkKinematicsRoundTrip(t *testing.T, name string, kinematics Kinematics, positions []Vector3d) {
+	t.Helper()
+	for _, position := range positions {
+		checkVector(t, name, kinematics.forward(kinematics.inverse(position)), position)
+	}
+}
+
+func TestCoreXYKinematics(t *testing.T) {
+	kinematics := newCoreXYKinematics(Vector3d{}, Vector3d{})
+
+	// A move along X turns both motors the same way, along Y opposite ways
+	checkVector(t, "X motors", kinematics.inverse(Vector3d{X: 1}), Vector3d{X: 1, Y: 1})
+	checkVector(t, "Y motors", kinematics.inverse(Vector3d{Y: 1}), Vector3d{X: 1, Y: -1})
+	checkKinematicsRoundTrip(t, "CoreXY", kinematics, []Vector3d{{}, {X: 10, Y: -5, Z: 2}, {X: -3, Y: 7.5}})
+}
+
+func TestHBotKinematics(t *testing.T) {
+	reversed := newHBotKinematics(-1, Vector3d{}, Vector3d{})
+
+	checkVector(t, "Y motors", reversed.inverse(Vector3d{Y: 1}), Vector3d{X: -1, Y: 1})
+	checkKinematicsRoundTrip(t, "H-bot", reversed, []Vector3d{{}, {X: 10, Y: -5, Z: 2}, {X: -3, Y: 7.5}})
+	checkKinematicsRoundTrip(t, "H-bot", newHBotKinematics(1, Vector3d{}, Vector3d{}), []Vector3d{{X: 10, Y: -5, Z: 2}})
+}
+
+func TestDeltaKinematics(t *testing.T) {
+	kinematics := newDeltaKinematics(100, 250, Vector3d{}, Vector3d{})
+
+	// Centered, the carriages are at the same height, above the effector
+	heights := kinematics.inverse(Vector3d{Z: 10})
+	checkFloat(t, "tower 2", heights.Y, heights.X)
+	checkFloat(t, "tower 3", heights.Z, heights.X)
+	checkFloat(t, "height", heights.X, 10+math.Sqrt(250*250-100*100))
+
+	checkKinematicsRoundTrip(t, "delta", kinematics, []Vector3d{
+		{}, {Z: 50}, {X: 40, Y: 0, Z: 5}, {X: -30, Y: 60, Z: 20}, {X: 25, Y: -80, Z: -10},
+	})
+}
+
+func TestDeltaKinematicsOutOfReach(t *testing.T) {
+	kinematics := newDeltaKinematics(100, 250, Vector3d{}, Vector3d{})
+
+	heights := kinematics.inverse(Vector3d{X: 400})
+	if !math.IsNaN(heights.X) {
+		t.Errorf("got heights %v, expected NaN out of reach of the rods", heights)
+	}
+}
+
+func TestDeltaMotorReach(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setKinematics(newDeltaKinematics(100, 250, Vector3d{X: 300, Y: 300, Z: 300}, Vector3d{X: 3000, Y: 3000, Z: 3000}))
+	planner := planLines(configuration, []string{"G1 X50 F600", "X400", "X0"})
+
+	var results []error
+	for _, movement := range planner.commandList.GetMovementList() {
+		results = append(results, planner.checkMotorReach(movement))
+	}
+	if len(results) != 3 || results[0] != nil || results[1] == nil || results[2] == nil {
+		t.Errorf("got %v, expected the movements through X400 to be out of reach", results)
+	}
+
+	// The machine does not send the positions out of reach
+	machine := newMachine(configuration, newMcuSimulator(nil))
+	machine.homed = true
+	if err := machine.moveTo(Vector3d{X: 400}); err == nil {
+		t.Errorf("the machine moved out of reach of the motors")
+	}
+	checkVector(t, "machine position", machine.getPosition(), Vector3d{})
+
+	// The linter reports the movements out of reach
+	linter := newGCodeLinter(newLinuxCNCDialect(), configuration)
+	findings := lintLines(linter, append(lintHeader, "G1 X50 F600", "X400"))
+	checkFindings(t, findings, LintFinding{Check: "motor-reach", Line: 4})
+}
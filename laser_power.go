@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Change the laser power with the S word of a motion line, without stopping.
+// The S carried from the previous lines and the S given while the laser is
+// off leave the power as it is.
+func (m *MotionPlanner) setLaserPower(command GCodeCommand) {
+	if !m.machine_configuration.laser_mode || m.spindle.direction == SpindleOff || !command.isOnLine("S") {
+		return
+	}
+	if power := command.params["S"]; power != m.spindle.speed {
+		m.setSpindle(m.spindle.direction, power)
+	}
+}
+
+// Check if a movement is a rapid, the laser is off during rapids
+func (m *MotionPlanner) isRapid(movement Movement) bool {
+	linear, ok := movement.(*LinearMovement)
+	return ok && linear.isRapid()
+}
+
+// Get the laser power at a velocity along a movement, as a fraction of the
+// full power. In dynamic mode (M4) the power follows the velocity, so the
+// energy per length stays the same while accelerating and in the corners.
+func (m *MotionPlanner) getLaserPower(movement Movement, spindle SpindleCommand, velocity float64) float64 {
+	if spindle.direction == SpindleOff || m.isRapid(movement) || m.machine_configuration.maxLaserPower <= 0 {
+		return 0
+	}
+
+	power := math.Min(spindle.speed/m.machine_configuration.maxLaserPower, 1)
+
+	if spindle.direction == SpindleCounterClockwise {
+		programmed := movement.getGcodeVelocity()
+		if programmed <= 0 {
+			return 0
+		}
+		power *= math.Min(velocity/programmed, 1)
+	}
+
+	return power
+}
+
+// Return a string representation of the laser power at the start, target and end velocities of a movement
+func (m *MotionPlanner) laserPowerString(movement Movement, spindle SpindleCommand) string {
+	return fmt.Sprintf("Power: %5.1f%% -> %5.1f%% -> %5.1f%%",
+		100*m.getLaserPower(movement, spindle, movement.getStartVelocity()),
+		100*m.getLaserPower(movement, spindle, movement.getTargetVelocity()),
+		100*m.getLaserPower(movement, spindle, movement.getEndVelocity()))
+}
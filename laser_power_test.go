@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Create a machine configuration in laser mode, S1000 is the full power
+func newLaserConfiguration() *MachineConfiguration {
+	configuration := defaultMachineConfiguration()
+	configuration.setLaserMode(true, 1000)
+	return configuration
+}
+
+func TestLaserOffDuringRapids(t *testing.T) {
+	configuration := newLaserConfiguration()
+	// The feed of the G1 is the rapid rate, it still cuts
+	planner := planLines(configuration, []string{"M3 S500", "G0 X10", fmt.Sprintf("G1 X20 F%g", configuration.rapidVelocity)})
+
+	movements := planner.commandList.GetMovementList()
+	spindle := SpindleCommand{direction: SpindleClockwise, speed: 500}
+	checkFloat(t, "rapid power", planner.getLaserPower(movements[0], spindle, 1), 0)
+	checkFloat(t, "feed power", planner.getLaserPower(movements[1], spindle, 1), 0.5)
+}
+
+func TestLaserPowerChanges(t *testing.T) {
+	planner := planLines(newLaserConfiguration(), []string{"M3 S500", "G1 X10 S800 F600", "X20", "M5", "G1 X30", "G1 X40 S200"})
+
+	// Only the S written on the motion lines while the laser is on change the power
+	type spindleState struct {
+		direction SpindleDirection
+		speed     float64
+	}
+	var states []spindleState
+	for _, spindle := range getCommands[*SpindleCommand](planner) {
+		states = append(states, spindleState{spindle.getDirection(), spindle.getSpeed()})
+	}
+	expected := []spindleState{{SpindleClockwise, 500}, {SpindleClockwise, 800}, {SpindleOff, 0}}
+	if fmt.Sprint(states) != fmt.Sprint(expected) {
+		t.Errorf("got spindle states %v, expected %v", states, expected)
+	}
+}
@@ -7,19 +7,25 @@ import (
 
 // Linear movement
 type LinearMovement struct {
-	start_position  Vector3d
-	end_position    Vector3d
+	start_position  AxisPosition
+	end_position    AxisPosition
 	start_velocity  float64
 	end_velocity    float64
 	target_velocity float64
 	gcodeVelocity   float64
+
+	// The auxiliary axes of the end follow the start unless they are set
+	auxiliary_motion bool
+
+	// Rapid movements (G0) do not cut, the laser is off along them
+	rapid bool
 }
 
 // Create a new linear movement
 func newLinearMovement(end_position Vector3d, gcodeVelocity float64) *LinearMovement {
 	return &LinearMovement{
-		start_position:  Vector3d{X: 0, Y: 0, Z: 0},
-		end_position:    end_position,
+		start_position:  newAxisPosition(Vector3d{X: 0, Y: 0, Z: 0}),
+		end_position:    newAxisPosition(end_position),
 		start_velocity:  gcodeVelocity,
 		target_velocity: gcodeVelocity,
 		gcodeVelocity:   gcodeVelocity,
@@ -27,14 +33,26 @@ func newLinearMovement(end_position Vector3d, gcodeVelocity float64) *LinearMove
 	}
 }
 
+// Create a new rapid movement
+func newRapidMovement(end_position Vector3d, rapidVelocity float64) *LinearMovement {
+	movement := newLinearMovement(end_position, rapidVelocity)
+	movement.rapid = true
+	return movement
+}
+
+// Check if the movement is a rapid
+func (m *LinearMovement) isRapid() bool {
+	return m.rapid
+}
+
 // Get the start position
 func (m *LinearMovement) getStartPosition() Vector3d {
-	return m.start_position
+	return m.start_position.cartesian()
 }
 
 // Get the end position
 func (m *LinearMovement) getEndPosition() Vector3d {
-	return m.end_position
+	return m.end_position.cartesian()
 }
 
 // Get the start velocity
@@ -62,12 +80,36 @@ func (m *LinearMovement) getTargetVelocity() float64 {
 
 // Set the start position
 func (m *LinearMovement) setStartPosition(start_position Vector3d) {
-	m.start_position = start_position
+	m.start_position = m.start_position.withCartesian(start_position)
 }
 
 // Set the end position
 func (m *LinearMovement) setEndPosition(end_position Vector3d) {
+	m.end_position = m.end_position.withCartesian(end_position)
+}
+
+// Get the start position of all the axes
+func (m *LinearMovement) getStartAxisPosition() AxisPosition {
+	return m.start_position
+}
+
+// Get the end position of all the axes
+func (m *LinearMovement) getEndAxisPosition() AxisPosition {
+	return m.end_position
+}
+
+// Set the start position of all the axes
+func (m *LinearMovement) setStartAxisPosition(start_position AxisPosition) {
+	m.start_position = start_position
+	if !m.auxiliary_motion {
+		m.end_position = m.end_position.withAuxiliary(start_position)
+	}
+}
+
+// Set the end position of all the axes
+func (m *LinearMovement) setEndAxisPosition(end_position AxisPosition) {
 	m.end_position = end_position
+	m.auxiliary_motion = true
 }
 
 // Set the start velocity
@@ -87,7 +129,7 @@ func (m *LinearMovement) setTargetVelocity(target_velocity float64) {
 
 // Get the start direction
 func (m *LinearMovement) getStartDirection() Vector3d {
-	start_direction := m.end_position.subtract(m.start_position)
+	start_direction := m.getEndPosition().subtract(m.getStartPosition())
 	if start_direction.length() == 0 {
 		return Vector3d{X: 0, Y: 0, Z: 0}
 	} else {
@@ -97,19 +139,47 @@ func (m *LinearMovement) getStartDirection() Vector3d {
 
 // Get the end direction
 func (m *LinearMovement) getEndDirection() Vector3d {
-	return m.start_position.subtract(m.end_position).normalize()
+	end_direction := m.getStartPosition().subtract(m.getEndPosition())
+	if end_direction.length() == 0 {
+		return Vector3d{X: 0, Y: 0, Z: 0}
+	} else {
+		return end_direction.normalize()
+	}
 }
 
-// Get the length of the movement
+// Get the length of the movement. Movements without X, Y or Z motion are
+// measured along the auxiliary axes, in degrees for the rotary axes.
 func (m *LinearMovement) getLength() float64 {
-	return m.end_position.subtract(m.start_position).length()
+	length := m.getEndPosition().subtract(m.getStartPosition()).length()
+	if length == 0 {
+		length = m.end_position.subtract(m.start_position).auxiliaryLength()
+	}
+	return length
+}
+
+// Get the position at a fraction of the movement, from 0 at the start to 1 at the end
+func (m *LinearMovement) getPositionAt(ratio float64) Vector3d {
+	return m.getStartPosition().Add(m.getEndPosition().subtract(m.getStartPosition()).scale(ratio))
+}
+
+// Get the part of the movement between two fractions of it, as a copy keeping
+// the velocities of the movement
+func (m *LinearMovement) getSegment(from float64, to float64) Movement {
+	segment := *m
+	segment.start_position = m.start_position.interpolate(m.end_position, from)
+	segment.end_position = m.start_position.interpolate(m.end_position, to)
+	return &segment
 }
 
 // Limit the velocity of the movement
 func (m *LinearMovement) limitVelocity(maxVelocity Vector3d) {
 
-	// Project the max velocity onto the direction of the movement
+	// Project the max velocity onto the direction of the movement, the
+	// movements of the auxiliary axes only are limited by the planner
 	direction := m.getStartDirection()
+	if direction.length() == 0 {
+		return
+	}
 
 	maxVelocityAlongDirection := math.Abs(maxVelocity.Dot(direction) / direction.length())
 
@@ -122,6 +192,10 @@ func (m *LinearMovement) limitVelocity(maxVelocity Vector3d) {
 func (m *LinearMovement) getMaxAcceleractionAlongMovement(maxAcceleration Vector3d) float64 {
 	// Project the max acceleration onto the direction of the movement
 	direction := m.getStartDirection()
+	if direction.length() == 0 {
+		// Only the auxiliary axes move
+		return math.Inf(1)
+	}
 
 	maxAccelerationAlongDirection := math.Abs(maxAcceleration.Dot(direction) / direction.length())
 
@@ -130,6 +204,9 @@ func (m *LinearMovement) getMaxAcceleractionAlongMovement(maxAcceleration Vector
 
 // Return a string representation of the movement
 func (m *LinearMovement) String() string {
-	return fmt.Sprintf("Linear move: Pos: %7.3f -> %7.3f  Velocity: %7.3f m/s -> %7.3f m/s -> %7.3f m/s", m.start_position, m.end_position, m.start_velocity, m.target_velocity, m.end_velocity)
-
+	description := fmt.Sprintf("Linear move: Pos: %7.3f -> %7.3f  Velocity: %7.3f m/s -> %7.3f m/s -> %7.3f m/s", m.getStartPosition(), m.getEndPosition(), m.start_velocity, m.target_velocity, m.end_velocity)
+	if !m.start_position.sameAuxiliary(m.end_position) {
+		description += fmt.Sprintf("  Axes: %v -> %v", m.start_position, m.end_position)
+	}
+	return description
 }
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Report of the lint command in JSON
+type LintReport struct {
+	File     string        `json:"file"`
+	Dialect  string        `json:"dialect"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+	Findings []LintFinding `json:"findings"`
+}
+
+// Create the report of the findings on a program, counting the errors and the warnings
+func newLintReport(filename string, dialect *GCodeDialect, findings []LintFinding) LintReport {
+	report := LintReport{File: filename, Dialect: dialect.getName(), Findings: findings}
+	if report.Findings == nil {
+		report.Findings = []LintFinding{}
+	}
+	for _, finding := range findings {
+		if finding.Severity == LintError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	return report
+}
+
+// Write the report in JSON, or as one line per finding followed by the counts
+func (r LintReport) write(w io.Writer, jsonOutput bool) error {
+	if jsonOutput {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	for _, finding := range r.Findings {
+		if _, err := fmt.Fprintln(w, finding); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s: %d errors, %d warnings\n", r.File, r.Errors, r.Warnings)
+	return err
+}
+
+// Parse a position such as 0,0,-100
+func parseVector3d(text string) (Vector3d, error) {
+	values := strings.Split(text, ",")
+	if len(values) != 3 {
+		return Vector3d{}, fmt.Errorf("expected x,y,z: %s", text)
+	}
+
+	var coordinates [3]float64
+	for i, value := range values {
+		coordinate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return Vector3d{}, fmt.Errorf("invalid coordinate: %s", value)
+		}
+		coordinates[i] = coordinate
+	}
+	return Vector3d{X: coordinates[0], Y: coordinates[1], Z: coordinates[2]}, nil
+}
+
+// Run the lint command: goCNC lint [flags] file.gcode. Return the exit
+// code, 1 when the program has errors and 2 when it cannot be linted.
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	jsonOutput := flags.Bool("json", false, "print the findings in JSON")
+	dialectName := flags.String("dialect", "linuxcnc", "dialect of the program: linuxcnc, grbl, marlin or fanuc")
+	toolCount := flags.Int("tools", 0, "number of tools of the tool changer, 0 to not check the tools")
+	softLimitMin := flags.String("min", "", "lower soft limits as x,y,z")
+	softLimitMax := flags.String("max", "", "upper soft limits as x,y,z")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: goCNC lint [flags] file.gcode")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	filename := flags.Arg(0)
+
+	dialect, err := getGCodeDialect(*dialectName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	configuration := defaultMachineConfiguration()
+	if *softLimitMin != "" || *softLimitMax != "" {
+		min, err := parseVector3d(*softLimitMin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "soft limits:", err)
+			return 2
+		}
+		max, err := parseVector3d(*softLimitMax)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "soft limits:", err)
+			return 2
+		}
+		configuration.setSoftLimits(min, max)
+	}
+
+	linter := newGCodeLinter(dialect, configuration)
+	linter.setToolCount(*toolCount)
+	findings, err := linter.lint(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	report := newLintReport(filename, dialect, findings)
+	if err := report.write(os.Stdout, *jsonOutput); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if report.Errors > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLintReportCounts(t *testing.T) {
+	findings := []LintFinding{
+		{File: "part.ngc", Line: 2, LineNumber: -1, Severity: LintWarning, Check: "modal-state", Message: "move before the units are set"},
+		{File: "part.ngc", Line: 5, LineNumber: 50, Severity: LintError, Check: "feed-rate", Message: "G1 feed move with F0"},
+	}
+	report := newLintReport("part.ngc", newFanucDialect(), findings)
+	if report.Errors != 1 || report.Warnings != 1 || report.Dialect != "Fanuc" {
+		t.Errorf("got %+v, expected 1 error and 1 warning in the Fanuc dialect", report)
+	}
+
+	var output bytes.Buffer
+	if err := report.write(&output, false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 3 || lines[2] != "part.ngc: 1 errors, 1 warnings" {
+		t.Errorf("got %q, expected a line per finding and the counts", lines)
+	}
+}
+
+func TestLintReportJSON(t *testing.T) {
+	findings := []LintFinding{{File: "part.ngc", Line: 5, LineNumber: 50, Severity: LintError, Check: "feed-rate", Message: "G1 feed move with F0"}}
+
+	var output bytes.Buffer
+	if err := newLintReport("part.ngc", newLinuxCNCDialect(), findings).write(&output, true); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(output.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON %s: %v", output.String(), err)
+	}
+	expected := map[string]interface{}{
+		"file":     "part.ngc",
+		"dialect":  "LinuxCNC",
+		"errors":   1.0,
+		"warnings": 0.0,
+		"findings": []interface{}{map[string]interface{}{
+			"file": "part.ngc", "line": 5.0, "lineNumber": 50.0, "severity": "error", "check": "feed-rate", "message": "G1 feed move with F0",
+		}},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("got %v, expected %v", decoded, expected)
+	}
+
+	// A clean program has an empty list of findings, not null
+	output.Reset()
+	if err := newLintReport("part.ngc", newLinuxCNCDialect(), nil).write(&output, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), `"findings": []`) {
+		t.Errorf("got %s, expected an empty list of findings", output.String())
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import "fmt"
+
+// Severity of the lint findings, a job with errors must not run
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// Finding of the linter on a line of a program. The fields are exported for
+// the JSON output.
+type LintFinding struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+
+	// N number of the line, -1 without one
+	LineNumber int `json:"lineNumber"`
+
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+// Return a string representation of the finding
+func (f LintFinding) String() string {
+	location := lineLocation(f.File, f.Line)
+	if f.LineNumber >= 0 {
+		location += fmt.Sprintf(" (N%d)", f.LineNumber)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", location, f.Severity, f.Message, f.Check)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Machine driven through the link to its controller
+type Machine struct {
+	state         MachineState
+	configuration *MachineConfiguration
+	link          MachineLink
+
+	// The soft limits and work offsets are only valid once homed
+	homed      bool
+	workOffset Vector3d
+
+	// Machine position and jog in progress
+	position   Vector3d
+	currentJog *Jog
+
+	// Parameters of the programs, the machine sets the probe results
+	parameters *GCodeParameters
+}
+
+// Create a new machine. Machines with homing switches start in alarm until
+// they are homed.
+func newMachine(configuration *MachineConfiguration, link MachineLink) *Machine {
+	state := StateIdle
+	if len(configuration.homingAxes) > 0 {
+		state = StateAlarm
+	}
+	return &Machine{state: state, configuration: configuration, link: link, parameters: newGCodeParameters()}
+}
+
+// Get the state
+func (m *Machine) getState() MachineState {
+	return m.state
+}
+
+// Check if the machine is homed
+func (m *Machine) isHomed() bool {
+	return m.homed
+}
+
+// Change the state, if the transition is allowed
+func (m *Machine) setState(state MachineState) error {
+	if !m.state.canTransitionTo(state) {
+		return fmt.Errorf("cannot go from %s to %s", m.state, state)
+	}
+	m.state = state
+	return nil
+}
+
+// Raise an alarm, the machine must be homed again since its position may be lost
+func (m *Machine) alarm() {
+	m.state = StateAlarm
+	m.homed = false
+}
+
+// Home the axes. The machine goes back to idle on success and in alarm on failure.
+func (m *Machine) home() error {
+	if err := m.setState(StateHoming); err != nil {
+		return err
+	}
+
+	if err := homeAxes(m.link, m.configuration.homingAxes); err != nil {
+		m.alarm()
+		return err
+	}
+
+	for _, axis := range m.configuration.homingAxes {
+		switch axis.axis {
+		case XAxis:
+			m.position.X = axis.homePosition
+		case YAxis:
+			m.position.Y = axis.homePosition
+		case ZAxis:
+			m.position.Z = axis.homePosition
+		}
+	}
+
+	m.homed = true
+	m.state = StateIdle
+	return nil
+}
+
+// Get the machine position
+func (m *Machine) getPosition() Vector3d {
+	return m.position
+}
+
+// Get a numbered parameter, parameters that were never set are 0
+func (m *Machine) getParameter(index int) float64 {
+	value, _ := m.parameters.get(ParameterReference{index: index})
+	return value
+}
+
+// Set a numbered parameter
+func (m *Machine) setParameter(index int, value float64) {
+	m.parameters.set(ParameterReference{index: index}, value)
+}
+
+// Get the parameters of the programs, shared with their interpreter
+func (m *Machine) getParameters() *GCodeParameters {
+	return m.parameters
+}
+
+// Move the tool to a machine position at the rapid velocity
+func (m *Machine) moveTo(target Vector3d) error {
+	if err := m.checkSoftLimits(target); err != nil {
+		return err
+	}
+
+	planner := newMotionPlanner(m.configuration)
+	movement := newRapidMovement(target, m.configuration.getRapidVelocity())
+	movement.setStartPosition(m.position)
+	if err := planner.checkMotorReach(movement); err != nil {
+		return err
+	}
+	planner.limitVelocity(movement)
+
+	if err := m.link.move(MoveMessage{start: m.position, target: target, velocity: movement.getTargetVelocity()}); err != nil {
+		m.alarm()
+		return err
+	}
+
+	m.position = target
+	return nil
+}
+
+// Read the arc voltage and update the torch height correction at the current and programmed velocities
+func (m *Machine) updateTorchHeight(controller *TorchHeightController, velocity float64, programmedVelocity float64) (float64, error) {
+	message, err := m.link.readArcVoltage()
+	if err != nil {
+		return controller.getCorrection(), err
+	}
+	return controller.update(message, velocity, programmedVelocity), nil
+}
+
+// Set the work offset, only once homed
+func (m *Machine) setWorkOffset(workOffset Vector3d) error {
+	if !m.homed {
+		return errors.New("work offsets are not valid before homing")
+	}
+	m.workOffset = workOffset
+	return nil
+}
+
+// Get the work offset, only once homed
+func (m *Machine) getWorkOffset() (Vector3d, error) {
+	if !m.homed {
+		return Vector3d{X: 0, Y: 0, Z: 0}, errors.New("work offsets are not valid before homing")
+	}
+	return m.workOffset, nil
+}
+
+// Check that a machine position is within the soft limits, only once homed
+func (m *Machine) checkSoftLimits(position Vector3d) error {
+	if !m.configuration.soft_limits {
+		return nil
+	}
+	if !m.homed {
+		return errors.New("soft limits are not valid before homing")
+	}
+
+	min := m.configuration.softLimitMin
+	max := m.configuration.softLimitMax
+	if position.X < min.X || position.Y < min.Y || position.Z < min.Z || position.X > max.X || position.Y > max.Y || position.Z > max.Z {
+		return fmt.Errorf("position %v is outside the soft limits", position)
+	}
+	return nil
+}
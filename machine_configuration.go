@@ -1,12 +1,65 @@
 package main
 
-import "math"
+import (
+	"math"
+	"sort"
+)
 
 type MachineConfiguration struct {
-	maxAcceleraction         Vector3d
-	maxVelocity              Vector3d
+	maxAcceleraction Vector3d
+	maxVelocity      Vector3d
+
+	// Velocity of the rapid movements, in units per minute as the F words
 	rapidVelocity            float64
 	path_deviation_tolerance float64
+
+	// Round the corners with arcs instead of slowing down into them
+	corner_blending bool
+
+	// Limits of the auxiliary axes, in degrees for the rotary axes. The axes
+	// without a limit use the lowest limit of the X, Y and Z axes.
+	maxAuxiliaryAcceleraction AxisPosition
+	maxAuxiliaryVelocity      AxisPosition
+
+	// Mapping of the axes to the motors, nil when the axes map 1:1 to the motors
+	kinematics Kinematics
+
+	// Mapping of the tool tip to the joints of a five axis machine
+	fiveAxisKinematics *FiveAxisKinematics
+
+	// Length of each tool, selected by the H word of G43.4
+	toolLengths map[int]float64
+
+	// Backlash of each axis and velocity at which it is taken up
+	backlash         Vector3d
+	backlashVelocity float64
+
+	// Leadscrew pitch error of each axis, nil when not compensated
+	pitchErrorTables [3]*PitchErrorTable
+
+	// Homing sequence of the axes
+	homingAxes []*HomingAxis
+
+	// Travel limits of the machine, checked once homed
+	soft_limits  bool
+	softLimitMin Vector3d
+	softLimitMax Vector3d
+
+	// The spindle is a laser, the S word giving its power up to maxLaserPower
+	laser_mode    bool
+	maxLaserPower float64
+
+	// Limits of the extruder only movements such as the retracts, and the
+	// pressure advance in seconds
+	maxExtrudeOnlyVelocity     float64
+	maxExtrudeOnlyAcceleration float64
+	pressureAdvance            float64
+
+	// Plasma torch switched by the spindle commands, nil without one
+	plasmaTorch *PlasmaTorch
+
+	// Position the axes return to with G28, and where they are once homed
+	referencePosition AxisPosition
 }
 
 // newMachineConfiguration creates a new machine configuration
@@ -14,6 +67,125 @@ func newMachineConfiguration(maxAcceleraction Vector3d, maxVelocity Vector3d, ra
 	return &MachineConfiguration{maxAcceleraction: maxAcceleraction, maxVelocity: maxVelocity, rapidVelocity: rapidVelocity, path_deviation_tolerance: path_deviation_tolerance}
 }
 
+// setCornerBlending enables or disables the geometric corner blending
+func (m *MachineConfiguration) setCornerBlending(corner_blending bool) {
+	m.corner_blending = corner_blending
+}
+
+// setAuxiliaryLimits sets the acceleration and velocity limits of an auxiliary axis
+func (m *MachineConfiguration) setAuxiliaryLimits(axis Axis, maxAcceleraction float64, maxVelocity float64) {
+	m.maxAuxiliaryAcceleraction = m.maxAuxiliaryAcceleraction.with(axis, maxAcceleraction)
+	m.maxAuxiliaryVelocity = m.maxAuxiliaryVelocity.with(axis, maxVelocity)
+}
+
+// setKinematics sets the mapping of the axes to the motors
+func (m *MachineConfiguration) setKinematics(kinematics Kinematics) {
+	m.kinematics = kinematics
+}
+
+// setFiveAxisKinematics sets the kinematics used by the tool center point control
+func (m *MachineConfiguration) setFiveAxisKinematics(fiveAxisKinematics *FiveAxisKinematics) {
+	m.fiveAxisKinematics = fiveAxisKinematics
+}
+
+// setToolLength sets the length of a tool
+func (m *MachineConfiguration) setToolLength(tool int, length float64) {
+	if m.toolLengths == nil {
+		m.toolLengths = make(map[int]float64)
+	}
+	m.toolLengths[tool] = length
+}
+
+// setBacklash sets the backlash of each axis and the velocity of the compensation
+func (m *MachineConfiguration) setBacklash(backlash Vector3d, backlashVelocity float64) {
+	m.backlash = backlash
+	m.backlashVelocity = backlashVelocity
+}
+
+// setPitchErrorTable sets the pitch error table of an axis
+func (m *MachineConfiguration) setPitchErrorTable(axis Axis, table *PitchErrorTable) {
+	m.pitchErrorTables[axis] = table
+}
+
+// setHomingAxes sets the homing sequence of the axes
+func (m *MachineConfiguration) setHomingAxes(homingAxes []*HomingAxis) {
+	m.homingAxes = homingAxes
+}
+
+// setSoftLimits sets the travel limits of the machine
+func (m *MachineConfiguration) setSoftLimits(softLimitMin Vector3d, softLimitMax Vector3d) {
+	m.soft_limits = true
+	m.softLimitMin = softLimitMin
+	m.softLimitMax = softLimitMax
+}
+
+// setLaserMode enables the laser mode, the S word of maxLaserPower is the full power
+func (m *MachineConfiguration) setLaserMode(laser_mode bool, maxLaserPower float64) {
+	m.laser_mode = laser_mode
+	m.maxLaserPower = maxLaserPower
+}
+
+// setReferencePosition sets the position the axes return to with G28
+func (m *MachineConfiguration) setReferencePosition(referencePosition AxisPosition) {
+	m.referencePosition = referencePosition
+}
+
+// setPlasmaTorch sets the plasma torch switched by the spindle commands
+func (m *MachineConfiguration) setPlasmaTorch(plasmaTorch *PlasmaTorch) {
+	m.plasmaTorch = plasmaTorch
+}
+
+// setExtruder sets the limits of the extruder only movements and the pressure advance
+func (m *MachineConfiguration) setExtruder(maxExtrudeOnlyVelocity float64, maxExtrudeOnlyAcceleration float64, pressureAdvance float64) {
+	m.maxExtrudeOnlyVelocity = maxExtrudeOnlyVelocity
+	m.maxExtrudeOnlyAcceleration = maxExtrudeOnlyAcceleration
+	m.pressureAdvance = pressureAdvance
+}
+
+// getReferencePosition gets the position an axis returns to with G28, its home
+// position without a configured reference, and false if it has neither
+func (m *MachineConfiguration) getReferencePosition(axis Axis) (float64, bool) {
+	if int(axis) < len(m.referencePosition) {
+		return m.referencePosition.get(axis), true
+	}
+	for _, homingAxis := range m.homingAxes {
+		if homingAxis.axis == axis {
+			return homingAxis.homePosition, true
+		}
+	}
+	return 0, false
+}
+
+// getPitchCorrection gets the pitch error correction of each axis at a position
+func (m *MachineConfiguration) getPitchCorrection(position Vector3d) Vector3d {
+	var correction [3]float64
+	for axis, table := range m.pitchErrorTables {
+		if table != nil {
+			correction[axis] = table.getCorrection(position.component(Axis(axis)))
+		}
+	}
+	return Vector3d{X: correction[0], Y: correction[1], Z: correction[2]}
+}
+
+// getPitchCrossings gets the fractions of a straight motion where an axis
+// crosses a position of its pitch error table, in order and ending with the
+// end of the motion
+func (m *MachineConfiguration) getPitchCrossings(start Vector3d, end Vector3d) []float64 {
+	fractions := []float64{1}
+	for axis, table := range m.pitchErrorTables {
+		if table != nil {
+			fractions = append(fractions, table.getCrossings(start.component(Axis(axis)), end.component(Axis(axis)))...)
+		}
+	}
+	sort.Float64s(fractions)
+	return fractions
+}
+
+// getRapidVelocity gets the velocity of the rapid movements in units per second
+func (m *MachineConfiguration) getRapidVelocity() float64 {
+	return m.rapidVelocity / 60
+}
+
 func (m *MachineConfiguration) getMaxVelocity(direction Vector3d) float64 {
 
 	// Project the max velocity vector onto the direction vector
@@ -21,6 +193,11 @@ func (m *MachineConfiguration) getMaxVelocity(direction Vector3d) float64 {
 	return maxVelocity
 }
 
+// getMaxVelocity_scalar gets the lowest velocity of the X, Y and Z axes
+func (m *MachineConfiguration) getMaxVelocity_scalar() float64 {
+	return math.Min(math.Min(m.maxVelocity.X, m.maxVelocity.Y), m.maxVelocity.Z)
+}
+
 func (m *MachineConfiguration) getMaxAcceleraction_scalar() float64 {
 	// Get the max acceleration scalar
 	maxAcceleraction_scalar := m.maxAcceleraction.X
@@ -0,0 +1,52 @@
+package main
+
+import "fmt"
+
+// Probe the surface at each point of a grid over a rectangle of the XY plane
+// to build a height map. The tool moves between the points at the clearance
+// height and probes down to the depth, all in work coordinates. The rows are
+// probed back and forth to shorten the moves.
+func (m *Machine) probeHeightMap(min Vector3d, max Vector3d, columns int, rows int, clearance float64, depth float64, feedrate float64) (*HeightMap, error) {
+	if columns < 1 || rows < 1 {
+		return nil, fmt.Errorf("invalid height map size %d x %d", columns, rows)
+	}
+	if depth >= clearance {
+		return nil, fmt.Errorf("probe depth %g is not below the clearance height %g", depth, clearance)
+	}
+
+	workOffset, err := m.getWorkOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	heightMap := newHeightMap(min, max, columns, rows)
+
+	if err := m.moveTo(Vector3d{X: m.position.X, Y: m.position.Y, Z: clearance + workOffset.Z}); err != nil {
+		return nil, err
+	}
+
+	for row := 0; row < rows; row++ {
+		for i := 0; i < columns; i++ {
+			column := i
+			if row%2 == 1 {
+				column = columns - 1 - i
+			}
+
+			point := heightMap.getPoint(column, row)
+			if err := m.moveTo(Vector3d{X: point.X, Y: point.Y, Z: clearance}.Add(workOffset)); err != nil {
+				return nil, err
+			}
+
+			if _, err := m.probe(ProbeToward, Vector3d{X: point.X, Y: point.Y, Z: depth}, feedrate); err != nil {
+				return nil, fmt.Errorf("probing point %d, %d: %v", column, row, err)
+			}
+			heightMap.setHeight(column, row, m.getParameter(probePositionParameter+2))
+
+			if err := m.moveTo(Vector3d{X: point.X, Y: point.Y, Z: clearance}.Add(workOffset)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return heightMap, nil
+}
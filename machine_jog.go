@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// Distance of a continuous jog when the machine has no soft limits
+const continuousJogDistance = 1e6
+
+// Start a jog from a $J= command. The machine must be idle or jogging, and
+// the target must be within the soft limits.
+func (m *Machine) jog(line string) (*Jog, error) {
+	command, err := parseJogCommand(line)
+	if err != nil {
+		return nil, err
+	}
+	m.queueJog()
+
+	// Absolute jogs in work coordinates need a valid work offset
+	workOffset := Vector3d{X: 0, Y: 0, Z: 0}
+	if !command.incremental && !command.machine {
+		if workOffset, err = m.getWorkOffset(); err != nil {
+			return nil, err
+		}
+	}
+
+	target := command.getTarget(m.position, workOffset)
+	if err := m.checkSoftLimits(target); err != nil {
+		return nil, err
+	}
+
+	return m.startJog(target, command.feedrate)
+}
+
+// Start a jog in a direction that goes on until it is cancelled or it reaches the soft limits
+func (m *Machine) jogContinuous(direction Vector3d, feedrate float64) (*Jog, error) {
+	if direction.length() == 0 {
+		return nil, errors.New("jog direction is empty")
+	}
+	direction = direction.normalize()
+	m.queueJog()
+
+	distance := continuousJogDistance
+	if m.configuration.soft_limits {
+		if !m.homed {
+			return nil, errors.New("soft limits are not valid before homing")
+		}
+		distance = m.getDistanceToSoftLimits(direction)
+	}
+
+	return m.startJog(m.position.Add(direction.scale(distance)), feedrate)
+}
+
+// Get the distance from the position to the soft limits in a direction
+func (m *Machine) getDistanceToSoftLimits(direction Vector3d) float64 {
+	min := m.configuration.softLimitMin
+	max := m.configuration.softLimitMax
+	distance := math.Inf(1)
+
+	for _, axis := range []Axis{XAxis, YAxis, ZAxis} {
+		d := direction.component(axis)
+		if d > 0 {
+			distance = math.Min(distance, (max.component(axis)-m.position.component(axis))/d)
+		} else if d < 0 {
+			distance = math.Min(distance, (min.component(axis)-m.position.component(axis))/d)
+		}
+	}
+
+	return math.Max(distance, 0)
+}
+
+// A new jog starts once the current one is finished, its target is relative
+// to the end of the current one
+func (m *Machine) queueJog() {
+	if m.state == StateJog && m.currentJog != nil {
+		m.position = m.currentJog.getEndPosition()
+	}
+}
+
+// Plan the jog with the velocity and acceleration limits of the machine
+func (m *Machine) startJog(target Vector3d, feedrate float64) (*Jog, error) {
+	if m.state != StateJog {
+		if err := m.setState(StateJog); err != nil {
+			return nil, err
+		}
+	}
+
+	planner := newMotionPlanner(m.configuration)
+	movement := newLinearMovement(target, feedrate/60)
+	movement.setStartPosition(m.position)
+	planner.limitVelocity(movement)
+
+	m.currentJog = newJog(m.position, target, movement.getTargetVelocity(), planner.getMaxAcceleration(movement))
+	return m.currentJog, nil
+}
+
+// Cancel the current jog at a time since its start, the machine decelerates to a stop
+func (m *Machine) cancelJog(time float64) {
+	if m.currentJog != nil {
+		m.currentJog.cancel(time)
+	}
+}
+
+// Finish the current jog, the machine is idle at its end position
+func (m *Machine) finishJog() {
+	if m.currentJog != nil {
+		m.position = m.currentJog.getEndPosition()
+		m.currentJog = nil
+	}
+	if m.state == StateJog {
+		m.state = StateIdle
+	}
+}
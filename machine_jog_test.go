@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+// Create a homed machine with soft limits from 0 to 15 on each axis
+func newJogMachine() *Machine {
+	configuration := defaultMachineConfiguration()
+	configuration.setSoftLimits(Vector3d{}, Vector3d{X: 15, Y: 15, Z: 15})
+	machine := newMachine(configuration, newMcuSimulator(nil))
+	machine.homed = true
+	return machine
+}
+
+func TestQueuedIncrementalJogs(t *testing.T) {
+	machine := newJogMachine()
+
+	first, err := machine.jog("$J=G91 X10 F600")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVector(t, "first jog end", first.getEndPosition(), Vector3d{X: 10})
+
+	// The second jog is queued after the first one, from X10
+	second, err := machine.jog("$J=G91 Y5 F600")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVector(t, "second jog start", second.getPositionAt(0), Vector3d{X: 10})
+	checkVector(t, "second jog end", second.getEndPosition(), Vector3d{X: 10, Y: 5})
+
+	// From there X10 more goes past the soft limits
+	if _, err := machine.jog("$J=G91 X10 F600"); err == nil {
+		t.Errorf("a queued jog past the soft limits is accepted")
+	}
+
+	machine.finishJog()
+	if machine.getState() != StateIdle {
+		t.Errorf("got %s, expected idle after the jogs", machine.getState())
+	}
+	checkVector(t, "machine position", machine.getPosition(), Vector3d{X: 10, Y: 5})
+}
+
+func TestQueuedContinuousJog(t *testing.T) {
+	machine := newJogMachine()
+	if _, err := machine.jog("$J=G53 X10 F600"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The continuous jog stops at the soft limits, 5 after the end of the first jog
+	jog, err := machine.jogContinuous(Vector3d{X: 1}, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVector(t, "continuous jog end", jog.getEndPosition(), Vector3d{X: 15})
+}
+
+func TestCancelledJog(t *testing.T) {
+	machine := newJogMachine()
+	jog, err := machine.jog("$J=G91 X10 F600")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cancelled while cruising, the jog decelerates to a stop before its end
+	machine.cancelJog(0.5)
+	end := jog.getEndPosition()
+	if end.X <= jog.getPositionAt(0.5).X || end.X >= 10 {
+		t.Errorf("got a cancelled jog ending at %v", end)
+	}
+	machine.finishJog()
+	checkVector(t, "machine position", machine.getPosition(), end)
+}
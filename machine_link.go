@@ -0,0 +1,72 @@
+package main
+
+// Move motors toward or away from their homing switches
+type HomingMoveMessage struct {
+	motors       []int
+	distances    []float64
+	velocity     float64
+	stopOnSwitch bool
+}
+
+// Result of a homing move, for each motor of the message
+type HomingMoveResult struct {
+	positions []float64
+	triggered []bool
+}
+
+// Set the position of motors once they are homed
+type SetPositionMessage struct {
+	motors    []int
+	positions []float64
+}
+
+// Move the tool in a straight line
+type MoveMessage struct {
+	start    Vector3d
+	target   Vector3d
+	velocity float64
+}
+
+// Move the tool in a straight line until the probe triggers
+type ProbeMoveMessage struct {
+	start    Vector3d
+	target   Vector3d
+	velocity float64
+	// Trigger on contact, or on loss of contact when moving away from the workpiece
+	toward bool
+}
+
+// Trigger event of a probe move, the position is where the tool stopped
+type ProbeMoveResult struct {
+	position  Vector3d
+	triggered bool
+}
+
+// Spindle encoder feedback, the position is in revolutions and the velocity in revolutions per second
+type EncoderFeedbackMessage struct {
+	position float64
+	velocity float64
+}
+
+// Arc voltage of the plasma torch, the arc is ok once transferred to the plate
+type ArcVoltageMessage struct {
+	voltage float64
+	arcOk   bool
+}
+
+// Create an interface for the link to the machine controller (MCU). The
+// ProtocolLink carries it over the messages of message.proto.
+type MachineLink interface {
+	// Move motors, each stopping on its switch if requested
+	homingMove(message HomingMoveMessage) (HomingMoveResult, error)
+	// Set the position of motors
+	setPosition(message SetPositionMessage) error
+	// Move the tool
+	move(message MoveMessage) error
+	// Move the tool until the probe triggers
+	probeMove(message ProbeMoveMessage) (ProbeMoveResult, error)
+	// Read the spindle encoder
+	readEncoder() (EncoderFeedbackMessage, error)
+	// Read the arc voltage of the plasma torch
+	readArcVoltage() (ArcVoltageMessage, error)
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"goCNC_protocol"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Link to the machine controller over a stream such as a serial port. Each
+// request is a length delimited message of message.proto, answered by one
+// response.
+type ProtocolLink struct {
+	writer io.Writer
+	reader *bufio.Reader
+}
+
+// Create a new link over a stream
+func newProtocolLink(stream io.ReadWriter) *ProtocolLink {
+	return &ProtocolLink{writer: stream, reader: bufio.NewReader(stream)}
+}
+
+// Send a request and wait for its response
+func (l *ProtocolLink) send(request *goCNC_protocol.Request) (*goCNC_protocol.Response, error) {
+	if err := writeDelimited(l.writer, request); err != nil {
+		return nil, err
+	}
+
+	response := &goCNC_protocol.Response{}
+	if err := protodelim.UnmarshalFrom(l.reader, response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// Move motors, each stopping on its switch if requested
+func (l *ProtocolLink) homingMove(message HomingMoveMessage) (HomingMoveResult, error) {
+	response, err := l.send(&goCNC_protocol.Request{Request: &goCNC_protocol.Request_HomingMove{HomingMove: &goCNC_protocol.HomingMove{
+		Motors:       motorsToProto(message.motors),
+		Distances:    message.distances,
+		Velocity:     message.velocity,
+		StopOnSwitch: message.stopOnSwitch,
+	}}})
+	if err != nil {
+		return HomingMoveResult{}, err
+	}
+
+	result := response.GetHomingMoveResult()
+	return HomingMoveResult{positions: result.GetPositions(), triggered: result.GetTriggered()}, nil
+}
+
+// Set the position of motors
+func (l *ProtocolLink) setPosition(message SetPositionMessage) error {
+	_, err := l.send(&goCNC_protocol.Request{Request: &goCNC_protocol.Request_SetPosition{SetPosition: &goCNC_protocol.SetPosition{
+		Motors:    motorsToProto(message.motors),
+		Positions: message.positions,
+	}}})
+	return err
+}
+
+// Move the tool
+func (l *ProtocolLink) move(message MoveMessage) error {
+	_, err := l.send(&goCNC_protocol.Request{Request: &goCNC_protocol.Request_Move{Move: &goCNC_protocol.Move{
+		Start:    vector3dToProto(message.start),
+		Target:   vector3dToProto(message.target),
+		Velocity: message.velocity,
+	}}})
+	return err
+}
+
+// Move the tool until the probe triggers
+func (l *ProtocolLink) probeMove(message ProbeMoveMessage) (ProbeMoveResult, error) {
+	response, err := l.send(&goCNC_protocol.Request{Request: &goCNC_protocol.Request_ProbeMove{ProbeMove: &goCNC_protocol.ProbeMove{
+		Start:    vector3dToProto(message.start),
+		Target:   vector3dToProto(message.target),
+		Velocity: message.velocity,
+		Toward:   message.toward,
+	}}})
+	if err != nil {
+		return ProbeMoveResult{}, err
+	}
+
+	result := response.GetProbeMoveResult()
+	return ProbeMoveResult{position: vector3dFromProto(result.GetPosition()), triggered: result.GetTriggered()}, nil
+}
+
+// Read the spindle encoder
+func (l *ProtocolLink) readEncoder() (EncoderFeedbackMessage, error) {
+	response, err := l.send(&goCNC_protocol.Request{Request: &goCNC_protocol.Request_ReadEncoder{ReadEncoder: &goCNC_protocol.ReadEncoder{}}})
+	if err != nil {
+		return EncoderFeedbackMessage{}, err
+	}
+
+	feedback := response.GetEncoderFeedback()
+	return EncoderFeedbackMessage{position: feedback.GetPosition(), velocity: feedback.GetVelocity()}, nil
+}
+
+// Read the arc voltage of the plasma torch
+func (l *ProtocolLink) readArcVoltage() (ArcVoltageMessage, error) {
+	response, err := l.send(&goCNC_protocol.Request{Request: &goCNC_protocol.Request_ReadArcVoltage{ReadArcVoltage: &goCNC_protocol.ReadArcVoltage{}}})
+	if err != nil {
+		return ArcVoltageMessage{}, err
+	}
+
+	voltage := response.GetArcVoltage()
+	return ArcVoltageMessage{voltage: voltage.GetVoltage(), arcOk: voltage.GetArcOk()}, nil
+}
+
+// Answer the requests of a protocol link with another link, such as the MCU
+// simulator, until the stream is closed
+func serveProtocolLink(stream io.ReadWriter, link MachineLink) error {
+	reader := bufio.NewReader(stream)
+	for {
+		request := &goCNC_protocol.Request{}
+		if err := protodelim.UnmarshalFrom(reader, request); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		response, err := handleRequest(request, link)
+		if err != nil {
+			response = &goCNC_protocol.Response{Error: err.Error()}
+		}
+		if err := writeDelimited(stream, response); err != nil {
+			return err
+		}
+	}
+}
+
+// Write a message after its length in a single write, a stream such as a pipe
+// blocks on the empty write of an empty message
+func writeDelimited(writer io.Writer, message proto.Message) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(protowire.AppendVarint(nil, uint64(len(data))), data...))
+	return err
+}
+
+// Execute a request with a link
+func handleRequest(request *goCNC_protocol.Request, link MachineLink) (*goCNC_protocol.Response, error) {
+	switch r := request.Request.(type) {
+	case *goCNC_protocol.Request_HomingMove:
+		result, err := link.homingMove(HomingMoveMessage{
+			motors:       motorsFromProto(r.HomingMove.GetMotors()),
+			distances:    r.HomingMove.GetDistances(),
+			velocity:     r.HomingMove.GetVelocity(),
+			stopOnSwitch: r.HomingMove.GetStopOnSwitch(),
+		})
+		return &goCNC_protocol.Response{Result: &goCNC_protocol.Response_HomingMoveResult{HomingMoveResult: &goCNC_protocol.HomingMoveResult{
+			Positions: result.positions,
+			Triggered: result.triggered,
+		}}}, err
+	case *goCNC_protocol.Request_SetPosition:
+		err := link.setPosition(SetPositionMessage{motors: motorsFromProto(r.SetPosition.GetMotors()), positions: r.SetPosition.GetPositions()})
+		return &goCNC_protocol.Response{}, err
+	case *goCNC_protocol.Request_Move:
+		err := link.move(MoveMessage{
+			start:    vector3dFromProto(r.Move.GetStart()),
+			target:   vector3dFromProto(r.Move.GetTarget()),
+			velocity: r.Move.GetVelocity(),
+		})
+		return &goCNC_protocol.Response{}, err
+	case *goCNC_protocol.Request_ProbeMove:
+		result, err := link.probeMove(ProbeMoveMessage{
+			start:    vector3dFromProto(r.ProbeMove.GetStart()),
+			target:   vector3dFromProto(r.ProbeMove.GetTarget()),
+			velocity: r.ProbeMove.GetVelocity(),
+			toward:   r.ProbeMove.GetToward(),
+		})
+		return &goCNC_protocol.Response{Result: &goCNC_protocol.Response_ProbeMoveResult{ProbeMoveResult: &goCNC_protocol.ProbeMoveResult{
+			Position:  vector3dToProto(result.position),
+			Triggered: result.triggered,
+		}}}, err
+	case *goCNC_protocol.Request_ReadEncoder:
+		feedback, err := link.readEncoder()
+		return &goCNC_protocol.Response{Result: &goCNC_protocol.Response_EncoderFeedback{EncoderFeedback: &goCNC_protocol.EncoderFeedback{
+			Position: feedback.position,
+			Velocity: feedback.velocity,
+		}}}, err
+	case *goCNC_protocol.Request_ReadArcVoltage:
+		voltage, err := link.readArcVoltage()
+		return &goCNC_protocol.Response{Result: &goCNC_protocol.Response_ArcVoltage{ArcVoltage: &goCNC_protocol.ArcVoltage{
+			Voltage: voltage.voltage,
+			ArcOk:   voltage.arcOk,
+		}}}, err
+	}
+	return nil, fmt.Errorf("unknown request: %v", request)
+}
+
+// Convert the motor numbers of a message
+func motorsToProto(motors []int) []int32 {
+	converted := make([]int32, len(motors))
+	for i, motor := range motors {
+		converted[i] = int32(motor)
+	}
+	return converted
+}
+
+// Convert the motor numbers of a protocol message
+func motorsFromProto(motors []int32) []int {
+	converted := make([]int, len(motors))
+	for i, motor := range motors {
+		converted[i] = int(motor)
+	}
+	return converted
+}
+
+// Convert a position of a message
+func vector3dToProto(v Vector3d) *goCNC_protocol.Vector3D {
+	return &goCNC_protocol.Vector3D{X: v.X, Y: v.Y, Z: v.Z}
+}
+
+// Convert a position of a protocol message
+func vector3dFromProto(v *goCNC_protocol.Vector3D) Vector3d {
+	return Vector3d{X: v.GetX(), Y: v.GetY(), Z: v.GetZ()}
+}
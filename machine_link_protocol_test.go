@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+// Create a protocol link to a simulator served at the other end of a pipe
+func newSimulatedProtocolLink(t *testing.T, simulator *McuSimulator) *ProtocolLink {
+	client, server := net.Pipe()
+	go serveProtocolLink(server, simulator)
+	t.Cleanup(func() { client.Close() })
+	return newProtocolLink(client)
+}
+
+func TestProtocolLinkHoming(t *testing.T) {
+	simulator := newMcuSimulator([]float64{-80, -83})
+	link := newSimulatedProtocolLink(t, simulator)
+	gantry := newHomingAxis(YAxis, 0, -1, 50, 5, 2, 0, 300, []int{0, 1})
+	gantry.setSquaringOffsets([]float64{0, 0.5})
+
+	if err := homeAxes(link, []*HomingAxis{gantry}); err != nil {
+		t.Fatal(err)
+	}
+	checkSwitchPosition(t, simulator, 0, -2)
+	checkSwitchPosition(t, simulator, 1, -2.5)
+}
+
+func TestProtocolLinkMessages(t *testing.T) {
+	simulator := newMcuSimulator([]float64{0})
+	simulator.setProbeContact(Vector3d{Z: -4})
+	simulator.setSpindleSpeed(600)
+	simulator.setArcVoltage(120)
+	link := newSimulatedProtocolLink(t, simulator)
+
+	if err := link.move(MoveMessage{start: Vector3d{}, target: Vector3d{X: 1}, velocity: 10}); err != nil {
+		t.Fatal(err)
+	}
+	checkVector(t, "tool position", simulator.getToolPosition(), Vector3d{X: 1})
+
+	probe, err := link.probeMove(ProbeMoveMessage{start: Vector3d{X: 1}, target: Vector3d{X: 1, Z: -10}, velocity: 1, toward: true})
+	if err != nil || !probe.triggered {
+		t.Fatalf("got %v and %v, expected the probe to trigger", probe, err)
+	}
+	checkVector(t, "probe position", probe.position, Vector3d{X: 1, Z: -4})
+	checkVector(t, "tool position after probing", simulator.getToolPosition(), Vector3d{X: 1, Z: -4})
+
+	encoder, err := link.readEncoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFloat(t, "encoder velocity", encoder.velocity, 10)
+	checkFloat(t, "encoder position", encoder.position, 10*encoderSamplePeriod)
+
+	voltage, err := link.readArcVoltage()
+	if err != nil || voltage.voltage != 120 || !voltage.arcOk {
+		t.Errorf("got %v and %v, expected 120 V with the arc ok", voltage, err)
+	}
+}
+
+func TestProtocolLinkError(t *testing.T) {
+	link := newSimulatedProtocolLink(t, newMcuSimulator([]float64{0}))
+
+	err := link.setPosition(SetPositionMessage{motors: []int{3}, positions: []float64{0}})
+	if err == nil || err.Error() != "unknown motor: 3" {
+		t.Fatalf("got %v, expected the error of the simulator", err)
+	}
+	// The link is still usable after an error
+	if err := link.setPosition(SetPositionMessage{motors: []int{0}, positions: []float64{5}}); err != nil {
+		t.Fatal(err)
+	}
+}
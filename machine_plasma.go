@@ -0,0 +1,42 @@
+package main
+
+import "math"
+
+// Distance between two readings of the arc voltage along a cut
+const torchHeightSampleLength = 0.5
+
+// Run a planned movement with the torch on. The movement is followed in
+// steps, the arc voltage read before each step corrects the height of the
+// torch where the velocity lets the height control act.
+func (m *Machine) runCut(planner *MotionPlanner, movement Movement, controller *TorchHeightController) error {
+	if err := m.setState(StateRun); err != nil {
+		return err
+	}
+
+	length := movement.getLength()
+	steps := int(math.Max(1, math.Ceil(length/torchHeightSampleLength)))
+	for i := 1; i <= steps; i++ {
+		startVelocity := planner.getVelocityAt(movement, length*float64(i-1)/float64(steps))
+		endVelocity := planner.getVelocityAt(movement, length*float64(i)/float64(steps))
+		correction, err := m.updateTorchHeight(controller, startVelocity, movement.getGcodeVelocity())
+		if err != nil {
+			m.alarm()
+			return err
+		}
+
+		target := movement.getPositionAt(float64(i) / float64(steps))
+		target.Z += correction
+		if err := m.checkSoftLimits(target); err != nil {
+			m.alarm()
+			return err
+		}
+		if err := m.link.move(MoveMessage{start: m.position, target: target, velocity: math.Max(startVelocity, endVelocity)}); err != nil {
+			m.alarm()
+			return err
+		}
+		m.position = target
+	}
+
+	m.state = StateIdle
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import "fmt"
+
+// Parameters holding the X, Y and Z probe trigger position (#5061 to #5063)
+// and whether the last probe was triggered (#5070)
+const (
+	probePositionParameter = 5061
+	probeSuccessParameter  = 5070
+)
+
+// Probe toward a target in work coordinates. The trigger position is recorded
+// in #5061 to #5063 and the machine stops there. G38.2 and G38.4 fail when the
+// probe is not triggered, G38.3 and G38.5 only clear #5070.
+func (m *Machine) probe(probeType ProbeType, target Vector3d, feedrate float64) (bool, error) {
+	if feedrate <= 0 {
+		return false, fmt.Errorf("%s needs a feed rate", probeType)
+	}
+
+	workOffset, err := m.getWorkOffset()
+	if err != nil {
+		return false, err
+	}
+
+	target = target.Add(workOffset)
+	if err := m.checkSoftLimits(target); err != nil {
+		return false, err
+	}
+	if target == m.position {
+		return false, fmt.Errorf("%s target is the current position", probeType)
+	}
+
+	if err := m.setState(StateRun); err != nil {
+		return false, err
+	}
+
+	// Probing moves use the velocity limits of the machine
+	planner := newMotionPlanner(m.configuration)
+	movement := newLinearMovement(target, feedrate/60)
+	movement.setStartPosition(m.position)
+	planner.limitVelocity(movement)
+
+	result, err := m.link.probeMove(ProbeMoveMessage{
+		start:    m.position,
+		target:   target,
+		velocity: movement.getTargetVelocity(),
+		toward:   probeType.isToward(),
+	})
+	if err != nil {
+		m.alarm()
+		return false, err
+	}
+
+	m.position = result.position
+	m.state = StateIdle
+
+	if !result.triggered {
+		m.setParameter(probeSuccessParameter, 0)
+		if probeType.failsWithoutTrigger() {
+			return false, fmt.Errorf("%s move finished without the probe triggering", probeType)
+		}
+		return false, nil
+	}
+
+	trigger := result.position.subtract(workOffset)
+	m.setParameter(probePositionParameter, trigger.X)
+	m.setParameter(probePositionParameter+1, trigger.Y)
+	m.setParameter(probePositionParameter+2, trigger.Z)
+	m.setParameter(probeSuccessParameter, 1)
+
+	return true, nil
+}
+
+// Run the probe of a program and continue its planning from where the probe
+// stopped. The program is interpreted before it runs, so the probe results
+// only change the positions of the movements, not the control flow.
+func (m *Machine) runProbe(planner *MotionPlanner, command GCodeCommand) (bool, error) {
+	probeType, ok := probeTypeFromCommand(command.command)
+	if !ok {
+		return false, fmt.Errorf("%s is not a probe", command.command)
+	}
+
+	target := Vector3d{X: command.params["X"], Y: command.params["Y"], Z: command.params["Z"]}
+	triggered, err := m.probe(probeType, target, command.params["F"])
+	if err != nil {
+		return false, err
+	}
+
+	workOffset, _ := m.getWorkOffset()
+	planner.continueFrom(m.position.subtract(workOffset))
+	return triggered, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Create a homed machine with a work offset of X100 Y50 and a probe contact
+// at Z-4 in machine coordinates
+func newProbeMachine(t *testing.T) (*Machine, *McuSimulator) {
+	simulator := newMcuSimulator(nil)
+	simulator.setProbeContact(Vector3d{Z: -4})
+	machine := newMachine(defaultMachineConfiguration(), simulator)
+	machine.homed = true
+	if err := machine.setWorkOffset(Vector3d{X: 100, Y: 50}); err != nil {
+		t.Fatal(err)
+	}
+	return machine, simulator
+}
+
+func TestProbeResults(t *testing.T) {
+	machine, _ := newProbeMachine(t)
+	if err := machine.moveTo(Vector3d{X: 110, Y: 50, Z: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	triggered, err := machine.probe(ProbeToward, Vector3d{X: 10, Z: -10}, 100)
+	if err != nil || !triggered {
+		t.Fatalf("got %t and %v, expected the probe to trigger", triggered, err)
+	}
+	checkVector(t, "machine position", machine.getPosition(), Vector3d{X: 110, Y: 50, Z: -4})
+
+	// The results are in work coordinates, in the parameters of the programs
+	interpreter := newGCodeInterpreter(newGCodeParser())
+	interpreter.setParameters(machine.getParameters())
+	commands, err := interpreter.fromString([]string{"G1 X#5061 Y#5062 Z[#5063 + #5070] F100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := commands[len(commands)-1].params
+	checkVector(t, "probe results", Vector3d{X: params["X"], Y: params["Y"], Z: params["Z"]}, Vector3d{X: 10, Z: -3})
+}
+
+func TestProbeWithoutTrigger(t *testing.T) {
+	machine, _ := newProbeMachine(t)
+	machine.setParameter(probeSuccessParameter, 1)
+	if err := machine.moveTo(Vector3d{X: 100, Y: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Probing away from a workpiece that is not touched never triggers
+	triggered, err := machine.probe(ProbeAwayNoError, Vector3d{Z: 10}, 100)
+	if err != nil || triggered {
+		t.Fatalf("got %t and %v, expected no trigger and no error", triggered, err)
+	}
+	checkFloat(t, "probe success", machine.getParameter(probeSuccessParameter), 0)
+
+	if _, err := machine.probe(ProbeAway, Vector3d{Z: 20}, 100); err == nil {
+		t.Errorf("G38.4 without a trigger succeeded")
+	}
+}
+
+func TestProbeBeforeHoming(t *testing.T) {
+	machine := newMachine(defaultMachineConfiguration(), newMcuSimulator(nil))
+
+	_, err := machine.probe(ProbeToward, Vector3d{Z: -10}, 100)
+	if err == nil || !strings.Contains(err.Error(), "before homing") {
+		t.Errorf("got %v, expected the work offset to be invalid before homing", err)
+	}
+}
+
+func TestProbeContinuesPlanning(t *testing.T) {
+	machine, _ := newProbeMachine(t)
+	planner := newMotionPlanner(machine.configuration)
+	commands := parseLines(newLinuxCNCDialect(), []string{"G0 X10 Y0 Z5", "G38.2 Z-10 F100", "G1 X20 F600", "Z2"})
+
+	rest := planner.planUntilProbe(commands)
+	if len(rest) != 3 || rest[0].command != "G38.2" {
+		t.Fatalf("got %v, expected to stop at the probe", rest)
+	}
+	if err := machine.moveTo(planner.commandList.getPreviousPosition().Add(Vector3d{X: 100, Y: 50})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := machine.runProbe(planner, rest[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next movements start where the probe stopped, Z stays there until set
+	if rest := planner.planUntilProbe(rest[1:]); len(rest) != 0 {
+		t.Fatalf("got %v after the last probe", rest)
+	}
+	checkEndPositions(t, planner, []Vector3d{{X: 10, Z: 5}, {X: 20, Z: -4}, {X: 20, Z: 2}})
+}
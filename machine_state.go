@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+// Machine state enum
+type MachineState int
+
+const (
+	StateIdle MachineState = iota
+	StateHoming
+	StateRun
+	StateHold
+	StateJog
+	StateAlarm
+	StateDoor
+	StateSleep
+)
+
+// Names of the machine states
+var machineStateNames = [...]string{"Idle", "Homing", "Run", "Hold", "Jog", "Alarm", "Door", "Sleep"}
+
+// States that can be reached from each state
+var machineStateTransitions = map[MachineState][]MachineState{
+	StateIdle:   {StateHoming, StateRun, StateJog, StateAlarm, StateDoor, StateSleep},
+	StateHoming: {StateIdle, StateAlarm, StateDoor},
+	StateRun:    {StateIdle, StateHold, StateAlarm, StateDoor},
+	StateHold:   {StateIdle, StateRun, StateAlarm, StateDoor},
+	StateJog:    {StateIdle, StateAlarm, StateDoor},
+	StateAlarm:  {StateIdle, StateHoming, StateSleep},
+	StateDoor:   {StateHold, StateIdle, StateAlarm},
+	StateSleep:  {StateAlarm},
+}
+
+// Check if the state can change to the given state
+func (s MachineState) canTransitionTo(state MachineState) bool {
+	for _, allowed := range machineStateTransitions[s] {
+		if allowed == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the name of the state
+func (s MachineState) String() string {
+	if s < 0 || int(s) >= len(machineStateNames) {
+		return fmt.Sprintf("MachineState(%d)", int(s))
+	}
+	return machineStateNames[s]
+}
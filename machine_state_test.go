@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestMachineStateNames(t *testing.T) {
+	for state, expected := range map[MachineState]string{StateJog: "Jog", StateSleep: "Sleep", -1: "MachineState(-1)", StateSleep + 1: "MachineState(8)"} {
+		if name := state.String(); name != expected {
+			t.Errorf("got %q, expected %q", name, expected)
+		}
+	}
+}
+
+func TestMachineStateTransitions(t *testing.T) {
+	if !StateIdle.canTransitionTo(StateRun) || !StateAlarm.canTransitionTo(StateHoming) {
+		t.Errorf("an allowed transition is refused")
+	}
+	if StateAlarm.canTransitionTo(StateRun) || StateSleep.canTransitionTo(StateIdle) || StateSleep.canTransitionTo(StateSleep+1) {
+		t.Errorf("a forbidden transition is allowed")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// Encoder readings without progress of the spindle after which a synchronized
+// motion is stopped, a second at the sample period of the simulated encoder
+const maxEncoderStallReadings = 1000
+
+// Run a synchronized motion from the machine position, following the spindle encoder until its end
+func (m *Machine) runSynchronizedMotion(motion *SynchronizedMotion) error {
+	motion.setStartPosition(m.position)
+	if err := checkPitch("synchronized motion", motion.getPitch()); err != nil {
+		return err
+	}
+	if err := m.checkSoftLimits(motion.getEndPosition()); err != nil {
+		return err
+	}
+	if err := m.setState(StateRun); err != nil {
+		return err
+	}
+
+	synchronizer := newSpindleSynchronizer(motion)
+	progress := math.Inf(-1)
+	stalledReadings := 0
+	for {
+		feedback, err := m.link.readEncoder()
+		if err != nil {
+			m.alarm()
+			return err
+		}
+		if feedback.velocity == 0 {
+			m.alarm()
+			return errors.New("spindle is not turning during synchronized motion")
+		}
+
+		// The encoder may report a velocity while its position is stuck
+		if revolutions := feedback.position * math.Copysign(1, feedback.velocity); revolutions > progress {
+			progress = revolutions
+			stalledReadings = 0
+		} else if stalledReadings++; stalledReadings >= maxEncoderStallReadings {
+			m.alarm()
+			return errors.New("spindle encoder stalled during synchronized motion")
+		}
+
+		position, done := synchronizer.update(feedback)
+		if position != m.position {
+			velocity := math.Abs(feedback.velocity) * motion.getPitch()
+			if err := m.link.move(MoveMessage{start: m.position, target: position, velocity: velocity}); err != nil {
+				m.alarm()
+				return err
+			}
+			m.position = position
+		}
+
+		if done {
+			break
+		}
+	}
+
+	m.state = StateIdle
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+// Create a homed machine with the spindle of its simulator turning at a speed in rpm
+func newSpindleMachine(speed float64) (*Machine, *McuSimulator) {
+	simulator := newMcuSimulator(nil)
+	simulator.setSpindleSpeed(speed)
+	machine := newMachine(defaultMachineConfiguration(), simulator)
+	machine.homed = true
+	return machine, simulator
+}
+
+func TestSynchronizedMotionFollowsTheEncoder(t *testing.T) {
+	for _, speed := range []float64{600, -600} {
+		machine, simulator := newSpindleMachine(speed)
+
+		if err := machine.runSynchronizedMotion(newSynchronizedMotion(Vector3d{Z: -2}, 1.5)); err != nil {
+			t.Fatalf("%g rpm: %v", speed, err)
+		}
+		if machine.getState() != StateIdle {
+			t.Errorf("%g rpm: got %s, expected idle", speed, machine.getState())
+		}
+		checkVector(t, "machine position", machine.getPosition(), Vector3d{Z: -2})
+		checkVector(t, "tool position", simulator.getToolPosition(), Vector3d{Z: -2})
+	}
+}
+
+func TestSynchronizedMotionWithoutSpindle(t *testing.T) {
+	machine, _ := newSpindleMachine(0)
+
+	if err := machine.runSynchronizedMotion(newSynchronizedMotion(Vector3d{Z: -2}, 1.5)); err == nil {
+		t.Fatal("the motion ran without the spindle")
+	}
+	if machine.getState() != StateAlarm {
+		t.Errorf("got %s, expected an alarm", machine.getState())
+	}
+}
+
+func TestSynchronizedMotionEncoderStall(t *testing.T) {
+	machine, simulator := newSpindleMachine(600)
+	simulator.stallEncoder()
+
+	if err := machine.runSynchronizedMotion(newSynchronizedMotion(Vector3d{Z: -2}, 1.5)); err == nil {
+		t.Fatal("the motion ran with a stalled encoder")
+	}
+	if machine.getState() != StateAlarm {
+		t.Errorf("got %s, expected an alarm", machine.getState())
+	}
+}
+
+func TestSynchronizedMotionWithoutPitch(t *testing.T) {
+	machine, _ := newSpindleMachine(600)
+
+	if err := machine.runSynchronizedMotion(newSynchronizedMotion(Vector3d{Z: -2}, 0)); err == nil {
+		t.Fatal("the motion ran without a pitch")
+	}
+	checkVector(t, "machine position", machine.getPosition(), Vector3d{})
+}
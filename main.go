@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"goCNC_protocol"
 	"log"
+	"os"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -26,7 +27,19 @@ import (
 // Solution 3
 //    Move calculation to the machine
 
+// Machine configuration of the examples and of the lint command
+func defaultMachineConfiguration() *MachineConfiguration {
+	return newMachineConfiguration(
+		Vector3d{X: 80, Y: 90, Z: 100},
+		Vector3d{X: 50, Y: 40, Z: 100},
+		100,
+		0.1)
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(runLint(os.Args[2:]))
+	}
 
 	person := &goCNC_protocol.Person{
 		Name: "John Doe",
@@ -43,20 +56,17 @@ func main() {
 
 	// Create a Motion Planner
 	// New machine configuration
-	gcode_parser := newGCodeParser()
-	parsedGCode := gcode_parser.fromFile("test.gcode")
+	gcode_interpreter := newGCodeInterpreter(newGCodeParser())
+	parsedGCode, err := gcode_interpreter.fromFile("test.gcode")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for _, command := range parsedGCode {
 		fmt.Println(command.description)
 	}
 
-	machineConfiguration := newMachineConfiguration(
-		Vector3d{X: 80, Y: 90, Z: 100},
-		Vector3d{X: 50, Y: 40, Z: 100},
-		100,
-		0.1)
-
-	motionPlanner := newMotionPlanner(machineConfiguration)
+	motionPlanner := newMotionPlanner(defaultMachineConfiguration())
 
 	motionPlanner.fromParsedGcode(parsedGCode)
 
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Distance under which the probe is considered touching the contact
+const probeContactTolerance = 1e-9
+
+// Time between two readings of the virtual spindle encoder, in seconds
+const encoderSamplePeriod = 0.001
+
+// Simulated machine controller, each motor has a homing switch at a position
+type McuSimulator struct {
+	positions       []float64
+	switchPositions []float64
+
+	// Position of the tool, it follows the moves and stops where the probe triggers
+	toolPosition Vector3d
+
+	// The probe touches the workpiece when the tool reaches the contact coordinate of an axis it moves along
+	probeContact *Vector3d
+
+	// Virtual spindle encoder, in revolutions and revolutions per second
+	spindlePosition float64
+	spindleVelocity float64
+	encoderStalled  bool
+
+	// Virtual arc voltage, the arc is lost at 0 V
+	arcVoltage float64
+}
+
+// Create a new MCU simulator with the position of the switch of each motor
+func newMcuSimulator(switchPositions []float64) *McuSimulator {
+	return &McuSimulator{positions: make([]float64, len(switchPositions)), switchPositions: switchPositions}
+}
+
+// Emulate a probe contact at a coordinate
+func (s *McuSimulator) setProbeContact(position Vector3d) {
+	s.probeContact = &position
+}
+
+// Turn the virtual spindle, the speed is in rpm and negative counterclockwise
+func (s *McuSimulator) setSpindleSpeed(speed float64) {
+	s.spindleVelocity = speed / 60
+}
+
+// Stop the position of the virtual encoder while it still reports the speed
+func (s *McuSimulator) stallEncoder() {
+	s.encoderStalled = true
+}
+
+// Set the virtual arc voltage of the plasma torch
+func (s *McuSimulator) setArcVoltage(voltage float64) {
+	s.arcVoltage = voltage
+}
+
+// Get the position of a motor
+func (s *McuSimulator) getPosition(motor int) float64 {
+	return s.positions[motor]
+}
+
+// Move motors, stopping each one on its switch if it is crossed
+func (s *McuSimulator) homingMove(message HomingMoveMessage) (HomingMoveResult, error) {
+	result := HomingMoveResult{}
+
+	for i, motor := range message.motors {
+		if motor < 0 || motor >= len(s.positions) {
+			return result, fmt.Errorf("unknown motor: %d", motor)
+		}
+
+		start := s.positions[motor]
+		target := start + message.distances[i]
+		switchPosition := s.switchPositions[motor]
+
+		crossed := (start <= switchPosition && switchPosition <= target) || (target <= switchPosition && switchPosition <= start)
+		if message.stopOnSwitch && crossed {
+			target = switchPosition
+		}
+
+		s.positions[motor] = target
+		result.positions = append(result.positions, target)
+		result.triggered = append(result.triggered, message.stopOnSwitch && crossed)
+	}
+
+	return result, nil
+}
+
+// Set the position of motors, the switches move with the coordinates
+func (s *McuSimulator) setPosition(message SetPositionMessage) error {
+	for i, motor := range message.motors {
+		if motor < 0 || motor >= len(s.positions) {
+			return fmt.Errorf("unknown motor: %d", motor)
+		}
+
+		s.switchPositions[motor] += message.positions[i] - s.positions[motor]
+		s.positions[motor] = message.positions[i]
+	}
+
+	return nil
+}
+
+// Get the position of the tool
+func (s *McuSimulator) getToolPosition() Vector3d {
+	return s.toolPosition
+}
+
+// Move the tool, the motors are only followed while homing
+func (s *McuSimulator) move(message MoveMessage) error {
+	s.toolPosition = message.target
+	return nil
+}
+
+// Move the tool toward the target, stopping where the probe contact is reached
+// or, when moving away, where it is left
+func (s *McuSimulator) probeMove(message ProbeMoveMessage) (ProbeMoveResult, error) {
+	result := s.getProbeStop(message)
+	s.toolPosition = result.position
+	return result, nil
+}
+
+// Get where a probe move stops
+func (s *McuSimulator) getProbeStop(message ProbeMoveMessage) ProbeMoveResult {
+	direction := message.target.subtract(message.start)
+	if s.probeContact == nil || direction.length() == 0 {
+		return ProbeMoveResult{position: message.target}
+	}
+
+	// Fraction of the move at which the contact coordinate of each axis is reached
+	ratio := math.Inf(1)
+	for _, axis := range []Axis{XAxis, YAxis, ZAxis} {
+		d := direction.component(axis)
+		if d == 0 {
+			continue
+		}
+		r := (s.probeContact.component(axis) - message.start.component(axis)) / d
+		if math.Abs(r*d) < probeContactTolerance {
+			r = 0
+		}
+		if r >= 0 && r <= 1 {
+			ratio = math.Min(ratio, r)
+		}
+	}
+
+	// Moving away, the contact is lost as soon as the tool leaves the workpiece
+	if !message.toward && ratio != 0 {
+		return ProbeMoveResult{position: message.target}
+	}
+	if math.IsInf(ratio, 1) {
+		return ProbeMoveResult{position: message.target}
+	}
+
+	return ProbeMoveResult{position: message.start.Add(direction.scale(ratio)), triggered: true}
+}
+
+// Read the virtual spindle encoder, the spindle turns for one sample period between readings
+func (s *McuSimulator) readEncoder() (EncoderFeedbackMessage, error) {
+	if !s.encoderStalled {
+		s.spindlePosition += s.spindleVelocity * encoderSamplePeriod
+	}
+	return EncoderFeedbackMessage{position: s.spindlePosition, velocity: s.spindleVelocity}, nil
+}
+
+// Read the virtual arc voltage
+func (s *McuSimulator) readArcVoltage() (ArcVoltageMessage, error) {
+	return ArcVoltageMessage{voltage: s.arcVoltage, arcOk: s.arcVoltage > 0}, nil
+}
@@ -5,24 +5,102 @@ import (
 	"math"
 )
 
+// Junctions closer than this angle to a straight line are considered tangent
+const tangentAngleTolerance = 1e-6
+
+// Feed rate mode enum (FeedUnitsPerMinute, FeedInverseTime, FeedUnitsPerRevolution)
+type FeedMode int
+
+const (
+	FeedUnitsPerMinute FeedMode = iota
+	FeedInverseTime
+	FeedUnitsPerRevolution
+)
+
 // Create an array of movements
 type MotionPlanner struct {
 	commandList           CommandList
 	machine_configuration *MachineConfiguration
+
+	// Canned cycles retract to the initial level (G98) or to the R plane (G99)
+	retractToInitialLevel bool
+
+	// The G28 intermediate points are distances from the current position (G91)
+	incrementalDistance bool
+
+	// Meaning of the F word (G93, G94, G95) and last programmed feed rate
+	feedMode FeedMode
+	feedrate float64
+
+	// Tool in the spindle, its length is used by G43.4 without an H word
+	tool int
+
+	// Tool center point control of each movement
+	toolCenterPoint map[Movement]*ToolCenterPoint
+
+	// Modal spindle and coolant states
+	spindle      SpindleCommand
+	coolantMist  bool
+	coolantFlood bool
+
+	// The plasma torch is on
+	torchOn bool
+
+	// Axes moved by a probe or a reference return, they stay where they were
+	// moved to until a line sets them
+	heldAxes map[string]bool
+
+	// The E words are distances from the previous extruder position (M83)
+	relativeExtrusion bool
+
+	// Surface of the workpiece the movements follow
+	heightMap              *HeightMap
+	heightMapSegmentLength float64
+
+	// Errors of the commands the planner left out
+	errors []error
 }
 
 // Create a new motion planner
 func newMotionPlanner(machineConfiguration *MachineConfiguration) *MotionPlanner {
-	return &MotionPlanner{commandList: CommandList{}, machine_configuration: machineConfiguration}
+	return &MotionPlanner{
+		commandList:           CommandList{},
+		machine_configuration: machineConfiguration,
+		retractToInitialLevel: true,
+	}
 }
 
-// Calculate radius according to the machine configuration path deviation tolerance
-func (m *MotionPlanner) calculateRadius(angle float64) float64 {
-	radius := m.machine_configuration.path_deviation_tolerance * math.Sin(angle/2) / (1 - math.Sin(angle/2))
+// Get the errors of the commands the planner left out
+func (m *MotionPlanner) getErrors() []error {
+	return m.errors
+}
+
+// Calculate radius according to the path deviation tolerance
+func (m *MotionPlanner) calculateRadius(angle float64, tolerance float64) float64 {
+	radius := tolerance * math.Sin(angle/2) / (1 - math.Sin(angle/2))
 	return radius
 }
 
-func (m *MotionPlanner) getMaxCornerVelocity(movement1 Movement, movement2 Movement) float64 {
+// Check if the junction of two linear movements deviates from a straight line by less than the tolerance
+func (m *MotionPlanner) isNaiveCamCollinear(movement1 Movement, movement2 Movement, tolerance float64) bool {
+	_, linear1 := movement1.(*LinearMovement)
+	_, linear2 := movement2.(*LinearMovement)
+	if !linear1 || !linear2 || tolerance <= 0 {
+		return false
+	}
+
+	line := movement2.getEndPosition().subtract(movement1.getStartPosition())
+	if line.length() == 0 {
+		return false
+	}
+
+	// Distance from the junction to the line joining the two outer points
+	deviation := movement1.getEndPosition().subtract(movement1.getStartPosition()).Cross(line).length() / line.length()
+
+	return deviation <= tolerance
+}
+
+func (m *MotionPlanner) getMaxCornerVelocity(movement1 Movement, movement2 Movement, pathControl *PathControl) float64 {
 	movement1_vector := movement1.getEndDirection()
 	movement2_vector := movement2.getStartDirection()
 
@@ -30,9 +108,17 @@ func (m *MotionPlanner) getMaxCornerVelocity(movement1 Movement, movement2 Movem
 		return 0
 	}
 
+	if pathControl.mode == PathExactStop {
+		return 0
+	}
+
+	if m.isNaiveCamCollinear(movement1, movement2, pathControl.naiveCamTolerance) {
+		return math.Inf(1)
+	}
+
 	angle := movement1_vector.AngleWith(movement2_vector)
 
-	radius := m.calculateRadius(angle)
+	radius := m.calculateRadius(angle, pathControl.tolerance)
 
 	acceleration := m.machine_configuration.getMaxAcceleractionForTwoVectors(movement1_vector, movement2_vector)
 
@@ -47,16 +133,16 @@ func (m *MotionPlanner) calculateMaxEndVelocity(movement Movement) float64 {
 	v_initial := movement.getStartVelocity()
 	distance := movement.getLength()
 
-	acceleration := movement.getMaxAcceleractionAlongMovement(m.machine_configuration.maxAcceleraction)
+	acceleration := m.getMaxAcceleration(movement)
 	max_end_velocity := math.Sqrt(math.Pow(v_initial, 2) + acceleration*distance)
 
 	return max_end_velocity
 }
 
-func (m *MotionPlanner) calculateJunctionVelocity(movement Movement, next_movement Movement) float64 {
+func (m *MotionPlanner) calculateJunctionVelocity(movement Movement, next_movement Movement, pathControl *PathControl) float64 {
 
 	// get the angle between the two movements
-	max_cornering := m.getMaxCornerVelocity(movement, next_movement)
+	max_cornering := m.getMaxCornerVelocity(movement, next_movement, pathControl)
 
 	// Find the minimum between the target velocity, the next move start velocity, the max cornering velocity and the max end feedrate
 	max_junction_velocity := math.Min(movement.getTargetVelocity(), next_movement.getStartVelocity())
@@ -72,7 +158,7 @@ func (m *MotionPlanner) calculateMaxStartVelocity(movement Movement, max_junctio
 	v_initial := max_junction_velocity
 	distance := movement.getLength()
 
-	acceleration := movement.getMaxAcceleractionAlongMovement(m.machine_configuration.maxAcceleraction)
+	acceleration := m.getMaxAcceleration(movement)
 	max_start_velocity := math.Sqrt(math.Pow(v_initial, 2) + acceleration*distance)
 	return max_start_velocity
 }
@@ -80,41 +166,263 @@ func (m *MotionPlanner) calculateMaxStartVelocity(movement Movement, max_junctio
 func (m *MotionPlanner) fromParsedGcode(gcodeList []GCodeCommand) {
 	// loop through the gcode commands
 	for _, gcodeLine := range gcodeList {
+		gcodeLine = m.followHeldAxes(gcodeLine)
+		if feedrate, ok := gcodeLine.params["F"]; ok {
+			m.feedrate = feedrate
+		}
 		if gcodeLine.command == "G0" {
 			// Create a new movement
-			movement := newLinearMovement(Vector3d{X: gcodeLine.params["X"], Y: gcodeLine.params["Y"], Z: gcodeLine.params["Z"]}, m.machine_configuration.rapidVelocity)
+			position := m.axisPositionFromParams(gcodeLine.params)
+			movement := newRapidMovement(position.cartesian(), m.machine_configuration.getRapidVelocity())
+			movement.setEndAxisPosition(position)
+			m.holdTorchHeight(movement, gcodeLine)
 
 			m.commandList.addMovement(movement)
 		} else if gcodeLine.command == "G1" {
 			// Create a new movement
-			movement := newLinearMovement(
-				Vector3d{X: gcodeLine.params["X"], Y: gcodeLine.params["Y"], Z: gcodeLine.params["Z"]},
-				gcodeLine.params["F"])
+			position := m.axisPositionFromParams(gcodeLine.params)
+			movement := newLinearMovement(position.cartesian(), 0)
+			movement.setEndAxisPosition(position)
+			m.setLaserPower(gcodeLine)
+			m.holdTorchHeight(movement, gcodeLine)
 
 			// Add the movement to the command list
-			m.commandList.addMovement(movement)
+			m.addFeedMovement(movement, gcodeLine.params["F"])
 		} else if gcodeLine.command == "G2" || gcodeLine.command == "G3" {
 			clockwise := gcodeLine.command == "G2"
-			// Create a new movement
+			// Create a new movement, the extruder follows the arc
+			position := m.axisPositionFromParams(gcodeLine.params)
 			movement := newArcMovement(
-
-				Vector3d{X: gcodeLine.params["X"], Y: gcodeLine.params["Y"], Z: gcodeLine.params["Z"]},
+				position.cartesian(),
 				Vector3d{X: gcodeLine.params["I"], Y: gcodeLine.params["J"], Z: gcodeLine.params["K"]},
-				gcodeLine.params["F"],
+				0,
 				clockwise,
 				ZAxis)
+			movement.setEndAxisPosition(position)
+			m.setLaserPower(gcodeLine)
+			m.holdTorchHeight(movement, gcodeLine)
 
 			// Add the movement to the command list
-			m.commandList.addMovement(movement)
+			m.addFeedMovement(movement, gcodeLine.params["F"])
+		} else if probeType, ok := probeTypeFromCommand(gcodeLine.command); ok {
+			// The probing movement stops where the probe triggers
+			movement := newLinearMovement(Vector3d{X: gcodeLine.params["X"], Y: gcodeLine.params["Y"], Z: gcodeLine.params["Z"]}, 0)
+			m.addFeedMovement(movement, gcodeLine.params["F"])
+			m.commandList.addCommand(newProbe(probeType))
+		} else if gcodeLine.command == "G33" {
+			// Spindle synchronized motion, K is the distance per revolution
+			end := Vector3d{X: gcodeLine.params["X"], Y: gcodeLine.params["Y"], Z: gcodeLine.params["Z"]}
+			if err := checkPitch(gcodeLine.command, gcodeLine.params["K"]); err != nil {
+				m.errors = append(m.errors, err)
+			} else {
+				m.commandList.addSynchronizedMotion(newSynchronizedMotion(end, gcodeLine.params["K"]))
+			}
+		} else if gcodeLine.command == "G33.1" {
+			if err := m.rigidTap(gcodeLine); err != nil {
+				m.errors = append(m.errors, err)
+			}
+		} else if gcodeLine.command == "G76" {
+			if err := m.expandThreadingCycle(gcodeLine); err != nil {
+				m.errors = append(m.errors, err)
+			}
+		} else if gcodeLine.command == "G4" {
+			m.commandList.addCommand(newDwell(gcodeLine.params["P"]))
+		} else if m.machine_configuration.plasmaTorch != nil && (gcodeLine.command == "M3" || gcodeLine.command == "M4" || gcodeLine.command == "M5") {
+			m.setTorch(gcodeLine.command != "M5", m.feedrate)
+		} else if gcodeLine.command == "M3" || gcodeLine.command == "M4" || gcodeLine.command == "M5" {
+			direction := SpindleOff
+			if gcodeLine.command == "M3" {
+				direction = SpindleClockwise
+			} else if gcodeLine.command == "M4" {
+				direction = SpindleCounterClockwise
+			}
+			m.setSpindle(direction, gcodeLine.params["S"])
+		} else if gcodeLine.command == "M7" || gcodeLine.command == "M8" || gcodeLine.command == "M9" {
+			m.coolantMist = gcodeLine.command == "M7" || (m.coolantMist && gcodeLine.command != "M9")
+			m.coolantFlood = gcodeLine.command == "M8" || (m.coolantFlood && gcodeLine.command != "M9")
+			m.commandList.addCommand(newCoolantCommand(m.coolantMist, m.coolantFlood))
+		} else if gcodeLine.command == "M6" {
+			m.tool = int(gcodeLine.params["T"])
+			m.commandList.addCommand(newToolChange(m.tool))
+		} else if gcodeLine.command == "M0" {
+			m.commandList.addCommand(newProgramStop(ProgramPause))
+		} else if gcodeLine.command == "M1" {
+			m.commandList.addCommand(newProgramStop(ProgramOptionalPause))
+		} else if gcodeLine.command == "M2" {
+			m.commandList.addCommand(newProgramStop(ProgramEnd))
+		} else if gcodeLine.command == "M30" {
+			m.commandList.addCommand(newProgramStop(ProgramEndRewind))
+		} else if gcodeLine.command == "M62" || gcodeLine.command == "M63" {
+			m.commandList.addCommand(newDigitalOutput(int(gcodeLine.params["P"]), gcodeLine.command == "M62", true))
+		} else if gcodeLine.command == "M64" || gcodeLine.command == "M65" {
+			m.commandList.addCommand(newDigitalOutput(int(gcodeLine.params["P"]), gcodeLine.command == "M64", false))
+		} else if gcodeLine.command == "G43.4" {
+			// H selects the tool whose length is used, the current tool without it
+			tool := m.tool
+			if h, ok := gcodeLine.params["H"]; ok {
+				tool = int(h)
+			}
+			m.commandList.addCommand(newToolCenterPoint(true, m.machine_configuration.toolLengths[tool]))
+		} else if gcodeLine.command == "G49" {
+			m.commandList.addCommand(newToolCenterPoint(false, 0))
+		} else if gcodeLine.command == "G93" {
+			m.feedMode = FeedInverseTime
+		} else if gcodeLine.command == "G94" {
+			m.feedMode = FeedUnitsPerMinute
+		} else if gcodeLine.command == "G95" {
+			m.feedMode = FeedUnitsPerRevolution
+		} else if gcodeLine.command == "G61" || gcodeLine.command == "G61.1" {
+			m.setPathControl(PathExactStop, 0, 0)
+		} else if gcodeLine.command == "G64" {
+			// Without P on the line, blend using the machine tolerance. The P
+			// and Q of a canned cycle are not tolerances.
+			tolerance := m.machine_configuration.path_deviation_tolerance
+			if gcodeLine.isOnLine("P") {
+				tolerance = gcodeLine.params["P"]
+			}
+			naiveCamTolerance := 0.0
+			if gcodeLine.isOnLine("Q") {
+				naiveCamTolerance = gcodeLine.params["Q"]
+			}
+			m.setPathControl(PathBlending, tolerance, naiveCamTolerance)
+		} else if gcodeLine.command == "M82" || gcodeLine.command == "M83" {
+			m.relativeExtrusion = gcodeLine.command == "M83"
+		} else if gcodeLine.command == "G92" {
+			// Only the extruder position is set, the E words of the next lines start from it
+			if e, ok := gcodeLine.params["E"]; ok {
+				m.commandList.previous_position = m.commandList.previous_position.with(EAxis, e)
+			}
+		} else if gcodeLine.command == "G98" || gcodeLine.command == "G99" {
+			m.retractToInitialLevel = gcodeLine.command == "G98"
+		} else if gcodeLine.command == "G90" || gcodeLine.command == "G91" {
+			m.incrementalDistance = gcodeLine.command == "G91"
+		} else if gcodeLine.command == "G28" || gcodeLine.command == "Home" {
+			m.returnToReference(gcodeLine)
+		} else if gcodeLine.command == "M104" || gcodeLine.command == "M109" {
+			m.commandList.addCommand(newTemperatureCommand(HeaterHotend, gcodeLine.params["S"], gcodeLine.command == "M109"))
+		} else if gcodeLine.command == "M140" || gcodeLine.command == "M190" {
+			m.commandList.addCommand(newTemperatureCommand(HeaterBed, gcodeLine.params["S"], gcodeLine.command == "M190"))
+		} else if messageType, ok := operatorMessageTypeFromCommand(gcodeLine.command); ok {
+			m.commandList.addCommand(newOperatorMessage(messageType, gcodeLine.description))
+		} else if isCannedCycle(gcodeLine.command) {
+			// Expand the drilling cycle into movements
+			m.expandCannedCycle(gcodeLine)
 		}
 	}
 }
 
+// Get the position of the axes, the auxiliary axes not in the parameters keep their position
+func (m *MotionPlanner) axisPositionFromParams(params map[string]float64) AxisPosition {
+	position := m.commandList.previous_position.withCartesian(Vector3d{X: params["X"], Y: params["Y"], Z: params["Z"]})
+	for axis := AAxis; axis <= EAxis; axis++ {
+		if val, ok := params[axis.String()]; ok {
+			if axis == EAxis && m.relativeExtrusion {
+				val += position.get(EAxis)
+			}
+			position = position.with(axis, val)
+		}
+	}
+	return position
+}
+
+// Limit the target velocity of the movement to the velocity of the machine axes and motors
+func (m *MotionPlanner) limitVelocity(movement Movement) {
+	movement.limitVelocity(m.machine_configuration.maxVelocity)
+
+	displacement := movement.getEndAxisPosition().subtract(movement.getStartAxisPosition())
+	velocity := displacement.limit(movement.getTargetVelocity(), movement.getLength(), m.machine_configuration.maxAuxiliaryVelocity, m.machine_configuration.getMaxVelocity_scalar())
+
+	if kinematics := m.machine_configuration.kinematics; kinematics != nil {
+		velocity = limitByMotorRatio(velocity, m.getMotorRatio(movement), kinematics.getMaxMotorVelocity())
+	}
+
+	// The linear joints of a five axis machine move differently than the tool tip
+	if m.isToolCenterPoint(movement) {
+		velocity = limitByMotorRatio(velocity, m.getJointRatio(movement), m.machine_configuration.maxVelocity)
+	}
+
+	// Retracts and other extruder only movements have their own limit
+	if displacement.isExtruderOnly() && m.machine_configuration.maxExtrudeOnlyVelocity > 0 {
+		velocity = math.Min(velocity, m.machine_configuration.maxExtrudeOnlyVelocity)
+	}
+
+	movement.setTargetVelocity(velocity)
+}
+
+// Get the maximum acceleration along the movement given the acceleration of the machine axes and motors
+func (m *MotionPlanner) getMaxAcceleration(movement Movement) float64 {
+	acceleration := movement.getMaxAcceleractionAlongMovement(m.machine_configuration.maxAcceleraction)
+
+	displacement := movement.getEndAxisPosition().subtract(movement.getStartAxisPosition())
+	acceleration = displacement.limit(acceleration, movement.getLength(), m.machine_configuration.maxAuxiliaryAcceleraction, m.machine_configuration.getMaxAcceleraction_scalar())
+
+	// A movement of no axis is not limited by any of them
+	if math.IsInf(acceleration, 1) {
+		acceleration = m.machine_configuration.getMaxAcceleraction_scalar()
+	}
+
+	if kinematics := m.machine_configuration.kinematics; kinematics != nil {
+		acceleration = limitByMotorRatio(acceleration, m.getMotorRatio(movement), kinematics.getMaxMotorAcceleration())
+	}
+
+	if m.isToolCenterPoint(movement) {
+		acceleration = limitByMotorRatio(acceleration, m.getJointRatio(movement), m.machine_configuration.maxAcceleraction)
+	}
+
+	if displacement.isExtruderOnly() && m.machine_configuration.maxExtrudeOnlyAcceleration > 0 {
+		acceleration = math.Min(acceleration, m.machine_configuration.maxExtrudeOnlyAcceleration)
+	}
+
+	return acceleration
+}
+
+// Add a movement to the command list and set its velocity from the programmed feed rate
+func (m *MotionPlanner) addFeedMovement(movement Movement, feedrate float64) {
+	m.commandList.addMovement(movement)
+
+	velocity := m.feedrateToVelocity(feedrate, movement.getLength())
+	movement.setGcodeVelocity(velocity)
+	movement.setTargetVelocity(velocity)
+	movement.setStartVelocity(velocity)
+}
+
+// Convert the programmed feed rate to a velocity in units per second
+func (m *MotionPlanner) feedrateToVelocity(feedrate float64, length float64) float64 {
+	switch m.feedMode {
+	case FeedInverseTime:
+		// The movement must be completed in 1/F minutes
+		return length * feedrate / 60
+	case FeedUnitsPerRevolution:
+		// The movement advances by F units per spindle revolution
+		return feedrate * m.spindle.speed / 60
+	}
+	return feedrate / 60
+}
+
+// Change the path control mode of the following junctions
+func (m *MotionPlanner) setPathControl(mode PathControlMode, tolerance float64, naiveCamTolerance float64) {
+	m.commandList.addCommand(newPathControl(mode, tolerance, naiveCamTolerance))
+}
+
+// Get, for each movement, the path control mode of its end junction. The
+// junctions before the first G61 or G64 blend with the machine tolerance.
+func (m *MotionPlanner) getPathControlList() []*PathControl {
+	return m.commandList.GetPathControlList(newPathControl(PathBlending, m.machine_configuration.path_deviation_tolerance, 0))
+}
+
+// Change the spindle state and add the command to the command list
+func (m *MotionPlanner) setSpindle(direction SpindleDirection, speed float64) {
+	m.spindle = SpindleCommand{direction: direction, speed: speed, laser: m.machine_configuration.laser_mode}
+	command := newSpindleCommand(direction, speed)
+	command.setLaser(m.machine_configuration.laser_mode)
+	m.commandList.addCommand(command)
+}
+
 func (m *MotionPlanner) calculateFeedrateProfile(movement Movement) {
 
 	// Calculate distance to accelerate to target feedrate
 
-	maxAcceleration := movement.getMaxAcceleractionAlongMovement(m.machine_configuration.maxAcceleraction)
+	maxAcceleration := m.getMaxAcceleration(movement)
 	accelerationDistance := (math.Pow(movement.getTargetVelocity(), 2) - math.Pow(movement.getStartVelocity(), 2)) / (2 * maxAcceleration)
 
 	// Calculate distance to deccelerate to end feedrate
@@ -151,7 +459,23 @@ func (m *MotionPlanner) calculateFeedrateProfile(movement Movement) {
 
 func (m *MotionPlanner) run() {
 
+	// Follow the surface of the workpiece before planning the velocities
+	if m.heightMap != nil {
+		m.levelMovements()
+	}
+
+	if m.machine_configuration.corner_blending {
+		m.blendCorners()
+	}
+
 	movements := m.commandList.GetMovementList()
+	full_stops := m.commandList.GetFullStopList()
+
+	m.toolCenterPoint = make(map[Movement]*ToolCenterPoint)
+	for i, toolCenterPoint := range m.commandList.GetToolCenterPointList() {
+		m.toolCenterPoint[movements[i]] = toolCenterPoint
+	}
+	path_controls := m.getPathControlList()
 
 	movements[0].setStartVelocity(0)
 
@@ -161,14 +485,19 @@ func (m *MotionPlanner) run() {
 		movement := movements[i]
 		next_movement := movements[i+1]
 
-		movement.limitVelocity(m.machine_configuration.maxVelocity)
+		m.limitVelocity(movement)
 
 		// Calculate the maximum velocity at the end of the current movement given the start velocity, distance and acceleration
 		max_end_velocity := m.calculateMaxEndVelocity(movement)
 		movement.setEndVelocity(max_end_velocity)
 
 		// Calculate the maximum junction velocity given the machine deviation tolerance an angle between the two movements
-		junction_velocity := m.calculateJunctionVelocity(movement, next_movement)
+		junction_velocity := m.calculateJunctionVelocity(movement, next_movement, path_controls[i])
+
+		// Commands such as a dwell or a tool change require the machine to be stopped
+		if full_stops[i] {
+			junction_velocity = 0
+		}
 
 		// Calculate the maximum start velocity given the calculated junction velocity, distance and acceleration
 		max_start_feedrate := m.calculateMaxStartVelocity(movement, junction_velocity)
@@ -195,10 +524,32 @@ func (m *MotionPlanner) run() {
 
 	}
 
+	// Compensate the mechanical errors of the machine on top of the planned movements
+	m.compensate()
+
 	fmt.Println("=====================================")
-	// Traverse the movement list
-	for i := 0; i < len(movements); i++ {
-		fmt.Println("[", i, "] ", movements[i])
+	// Traverse the command list, other commands are passed through in order with the movements
+	spindles := m.commandList.GetSpindleList()
+	torches := m.commandList.GetTorchList()
+	i = 0
+	for _, command := range m.commandList.arr {
+		switch command.(type) {
+		case Movement:
+			if m.machine_configuration.laser_mode {
+				fmt.Println("[", i, "] ", command, m.laserPowerString(command.(Movement), spindles[i]))
+			} else if torches[i] {
+				fmt.Println("[", i, "] ", command, m.heightControlString(command.(Movement)))
+			} else if m.isToolCenterPoint(command.(Movement)) {
+				fmt.Println("[", i, "] ", command, m.jointPositionString(command.(Movement)))
+			} else if m.isExtruding(command.(Movement)) {
+				fmt.Println("[", i, "] ", command, m.extruderVelocityString(command.(Movement)))
+			} else {
+				fmt.Println("[", i, "] ", command)
+			}
+			i++
+		case Command:
+			fmt.Println("       ", command)
+		}
 	}
 
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+)
+
+// Parse lines and plan their commands, without running the planner
+func planLines(configuration *MachineConfiguration, lines []string) *MotionPlanner {
+	planner := newMotionPlanner(configuration)
+	planner.fromParsedGcode(parseLines(newLinuxCNCDialect(), lines))
+	return planner
+}
+
+// Get the end position of each planned movement
+func getEndPositions(planner *MotionPlanner) []Vector3d {
+	var positions []Vector3d
+	for _, movement := range planner.commandList.GetMovementList() {
+		positions = append(positions, movement.getEndPosition())
+	}
+	return positions
+}
+
+// Check the end positions of the planned movements
+func checkEndPositions(t *testing.T, planner *MotionPlanner, expected []Vector3d) {
+	t.Helper()
+	actual := getEndPositions(planner)
+	if len(actual) != len(expected) {
+		t.Fatalf("got %d movements %v, expected %d %v", len(actual), actual, len(expected), expected)
+	}
+	for i := range expected {
+		checkVector(t, "movement end", actual[i], expected[i])
+	}
+}
+
+// Get the commands of a type in the command list, in order
+func getCommands[T Command](planner *MotionPlanner) []T {
+	var commands []T
+	for _, item := range planner.commandList.arr {
+		if command, ok := item.(T); ok {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+func TestPlannerLinearMovements(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"G0 X10 Y5", "G1 Z-1 F600", "X0"})
+
+	checkEndPositions(t, planner, []Vector3d{{X: 10, Y: 5}, {X: 10, Y: 5, Z: -1}, {X: 0, Y: 5, Z: -1}})
+	movements := planner.commandList.GetMovementList()
+	// The rapid rate is in units per minute as the F words
+	checkFloat(t, "rapid velocity", movements[0].getGcodeVelocity(), defaultMachineConfiguration().rapidVelocity/60)
+	checkFloat(t, "feed velocity", movements[1].getGcodeVelocity(), 10)
+	checkFloat(t, "modal feed velocity", movements[2].getGcodeVelocity(), 10)
+}
+
+func TestPlannerMachineCommands(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"M7", "M8", "M9", "T2 M6", "M1", "M30"})
+
+	coolants := getCommands[*CoolantCommand](planner)
+	if len(coolants) != 3 {
+		t.Fatalf("got %d coolant commands, expected 3", len(coolants))
+	}
+	for i, expected := range [][2]bool{{true, false}, {true, true}, {false, false}} {
+		if coolants[i].getMist() != expected[0] || coolants[i].getFlood() != expected[1] {
+			t.Errorf("coolant %d: got %v, expected mist %t and flood %t", i, coolants[i], expected[0], expected[1])
+		}
+	}
+
+	if toolChanges := getCommands[*ToolChange](planner); len(toolChanges) != 1 || toolChanges[0].getTool() != 2 {
+		t.Errorf("got tool changes %v, expected T2", toolChanges)
+	}
+
+	stops := getCommands[*ProgramStop](planner)
+	if len(stops) != 2 || stops[0].getStopType() != ProgramOptionalPause || stops[1].getStopType() != ProgramEndRewind {
+		t.Errorf("got program stops %v, expected M1 and M30", stops)
+	}
+}
+
+func TestPlannerFeedModes(t *testing.T) {
+	// G94 F is in units per minute, G93 F is the inverse of the minutes of
+	// the move and G95 F is in units per spindle revolution
+	planner := planLines(defaultMachineConfiguration(), []string{
+		"G94 G1 X10 F600",
+		"G93 G1 X30 F6",
+		"G94 M3 S1200",
+		"G95 G1 X40 F0.1",
+	})
+
+	movements := planner.commandList.GetMovementList()
+	checkFloat(t, "G94 velocity", movements[0].getGcodeVelocity(), 10)
+	checkFloat(t, "G93 velocity", movements[1].getGcodeVelocity(), 2)
+	checkFloat(t, "G95 velocity", movements[2].getGcodeVelocity(), 2)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Number of segments a movement is divided in to find the motor velocities
+const motorRatioSegments = 16
+
+// Get, for each motor, the highest ratio between the motor displacement and
+// the cartesian displacement along the movement. Non-linear kinematics such as
+// delta change the ratio along the movement, so it is sampled in segments.
+func (m *MotionPlanner) getMotorRatio(movement Movement) Vector3d {
+	kinematics := m.machine_configuration.kinematics
+
+	return getDisplacementRatio(movement, func(position Vector3d, axes AxisPosition) Vector3d {
+		return kinematics.inverse(position)
+	})
+}
+
+// Get, for each linear joint of a five axis machine, the highest ratio between
+// the joint displacement and the tool tip displacement along the movement
+func (m *MotionPlanner) getJointRatio(movement Movement) Vector3d {
+	kinematics := m.machine_configuration.fiveAxisKinematics
+	toolLength := m.toolCenterPoint[movement].getToolLength()
+
+	return getDisplacementRatio(movement, func(position Vector3d, axes AxisPosition) Vector3d {
+		return kinematics.inverse(position, axes, toolLength)
+	})
+}
+
+// Check that the motors reach every point of a movement. The kinematics give
+// non finite motor positions for the positions out of their reach.
+func (m *MotionPlanner) checkMotorReach(movement Movement) error {
+	kinematics := m.machine_configuration.kinematics
+	if kinematics == nil {
+		return nil
+	}
+	for i := 0; i <= motorRatioSegments; i++ {
+		position := movement.getPositionAt(float64(i) / motorRatioSegments)
+		if !kinematics.inverse(position).isFinite() {
+			return fmt.Errorf("position %v is out of reach of the motors", position)
+		}
+	}
+	return nil
+}
+
+// Sample the movement in segments and get, for each component of the
+// transformed position, the highest ratio between its displacement and the
+// length of the segment
+func getDisplacementRatio(movement Movement, transform func(Vector3d, AxisPosition) Vector3d) Vector3d {
+	segmentLength := movement.getLength() / motorRatioSegments
+
+	ratio := Vector3d{X: 0, Y: 0, Z: 0}
+	if segmentLength == 0 {
+		return ratio
+	}
+
+	startAxes := movement.getStartAxisPosition()
+	endAxes := movement.getEndAxisPosition()
+
+	previous := transform(movement.getPositionAt(0), startAxes)
+	for i := 1; i <= motorRatioSegments; i++ {
+		fraction := float64(i) / motorRatioSegments
+		current := transform(movement.getPositionAt(fraction), startAxes.interpolate(endAxes, fraction))
+		displacement := current.subtract(previous)
+
+		ratio.X = math.Max(ratio.X, math.Abs(displacement.X)/segmentLength)
+		ratio.Y = math.Max(ratio.Y, math.Abs(displacement.Y)/segmentLength)
+		ratio.Z = math.Max(ratio.Z, math.Abs(displacement.Z)/segmentLength)
+
+		previous = current
+	}
+
+	return ratio
+}
+
+// Limit a cartesian velocity or acceleration so that no motor exceeds its maximum
+func limitByMotorRatio(value float64, ratio Vector3d, maximum Vector3d) float64 {
+	for _, limit := range [][2]float64{{ratio.X, maximum.X}, {ratio.Y, maximum.Y}, {ratio.Z, maximum.Z}} {
+		if limit[0] > 0 && limit[1] > 0 && value > limit[1]/limit[0] {
+			value = limit[1] / limit[0]
+		}
+	}
+	return value
+}
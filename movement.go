@@ -15,9 +15,15 @@ type Movement interface {
 	setStartVelocity(float64)
 	setEndVelocity(float64)
 	setTargetVelocity(float64)
+	getStartAxisPosition() AxisPosition
+	getEndAxisPosition() AxisPosition
+	setStartAxisPosition(AxisPosition)
+	setEndAxisPosition(AxisPosition)
 	getStartDirection() Vector3d
 	getEndDirection() Vector3d
 	getLength() float64
+	getPositionAt(float64) Vector3d
+	getSegment(float64, float64) Movement
 	limitVelocity(Vector3d)
 	getMaxAcceleractionAlongMovement(Vector3d) float64
 }
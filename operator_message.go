@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operator message type enum (MessageOperator, MessageDebug, MessagePrint)
+type OperatorMessageType int
+
+const (
+	MessageOperator OperatorMessageType = iota
+	MessageDebug
+	MessagePrint
+)
+
+// Get the message type of a message command (MSG, DEBUG, PRINT)
+func operatorMessageTypeFromCommand(command string) (OperatorMessageType, bool) {
+	switch command {
+	case "MSG":
+		return MessageOperator, true
+	case "DEBUG":
+		return MessageDebug, true
+	case "PRINT":
+		return MessagePrint, true
+	}
+	return MessageOperator, false
+}
+
+// Return the command of the message type
+func (o OperatorMessageType) String() string {
+	switch o {
+	case MessageDebug:
+		return "DEBUG"
+	case MessagePrint:
+		return "PRINT"
+	}
+	return "MSG"
+}
+
+// Parse a comment such as (MSG, Change the tool) into its message type and
+// text. The keyword is not case sensitive.
+func parseOperatorMessage(comment string) (OperatorMessageType, string, bool) {
+	comma := strings.IndexByte(comment, ',')
+	if comma < 0 {
+		return MessageOperator, "", false
+	}
+	messageType, ok := operatorMessageTypeFromCommand(strings.ToUpper(strings.TrimSpace(comment[:comma])))
+	return messageType, strings.TrimSpace(comment[comma+1:]), ok
+}
+
+// Show a message to the operator (MSG), or log it (DEBUG, PRINT)
+type OperatorMessage struct {
+	messageType OperatorMessageType
+	text        string
+}
+
+// Create a new operator message
+func newOperatorMessage(messageType OperatorMessageType, text string) *OperatorMessage {
+	return &OperatorMessage{messageType: messageType, text: text}
+}
+
+// Get the message type
+func (o *OperatorMessage) getMessageType() OperatorMessageType {
+	return o.messageType
+}
+
+// Get the message text
+func (o *OperatorMessage) getText() string {
+	return o.text
+}
+
+// Messages are delivered with the motion
+func (o *OperatorMessage) requiresFullStop() bool {
+	return false
+}
+
+// Return a string representation of the operator message
+func (o *OperatorMessage) String() string {
+	return fmt.Sprintf("Message:     %s  %s", o.messageType, o.text)
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestParseOperatorMessage(t *testing.T) {
+	for _, test := range []struct {
+		comment     string
+		messageType OperatorMessageType
+		text        string
+		ok          bool
+	}{
+		{"MSG, Change the tool", MessageOperator, "Change the tool", true},
+		{" debug ,depth #1", MessageDebug, "depth #1", true},
+		{"PRINT,", MessagePrint, "", true},
+		// Comments without a message keyword are not messages
+		{"MSG Change the tool", MessageOperator, "", false},
+		{"NOTE, not a message", MessageOperator, "", false},
+	} {
+		messageType, text, ok := parseOperatorMessage(test.comment)
+		if ok != test.ok || (ok && (messageType != test.messageType || text != test.text)) {
+			t.Errorf("(%s): got %s %q %t, expected %s %q %t", test.comment, messageType, text, ok, test.messageType, test.text, test.ok)
+		}
+	}
+}
+
+func TestInlineComments(t *testing.T) {
+	commands := parseLines(newLinuxCNCDialect(), []string{"G1 X10 (MSG, Cutting) Y5 F100 ; Z-1"})
+
+	var move GCodeCommand
+	var messages []string
+	for _, command := range commands {
+		if command.command == "G1" {
+			move = command
+		}
+		if command.command == "MSG" {
+			messages = append(messages, command.description)
+		}
+	}
+	if move.params["X"] != 10 || move.params["Y"] != 5 || move.isOnLine("Z") {
+		t.Errorf("got %v, expected the words around the comments", move.params)
+	}
+	if len(messages) != 1 || messages[0] != "Cutting" {
+		t.Errorf("got messages %v, expected Cutting", messages)
+	}
+}
+
+func TestOperatorMessagesBeforeMovements(t *testing.T) {
+	// The debug messages show the values of the parameters
+	interpreter := newGCodeInterpreter(newGCodeParser())
+	commands, err := interpreter.fromString([]string{"#1 = 2.5", "(DEBUG, depth #1)", "G1 X10 F100", "(MSG, Done)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	planner := newMotionPlanner(defaultMachineConfiguration())
+	planner.fromParsedGcode(commands)
+
+	messages := planner.commandList.GetMessageList()
+	if len(messages) != 2 || len(messages[0]) != 1 || len(messages[1]) != 1 {
+		t.Fatalf("got messages %v, expected one before and one after the movement", messages)
+	}
+	if messages[0][0].getMessageType() != MessageDebug || messages[0][0].getText() != "depth 2.5" {
+		t.Errorf("got %v, expected the debug message with the depth", messages[0][0])
+	}
+	if messages[1][0].getMessageType() != MessageOperator || messages[1][0].getText() != "Done" {
+		t.Errorf("got %v, expected the operator message", messages[1][0])
+	}
+}
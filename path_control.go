@@ -0,0 +1,55 @@
+package main
+
+import "fmt"
+
+// Path control mode enum (PathExactStop, PathBlending)
+type PathControlMode int
+
+const (
+	PathExactStop PathControlMode = iota
+	PathBlending
+)
+
+// Set how the movements are joined (G61, G61.1, G64)
+type PathControl struct {
+	mode PathControlMode
+
+	// Maximum deviation from the programmed corner when blending (G64 P)
+	tolerance float64
+
+	// Maximum deviation for consecutive linear moves to be considered collinear (G64 Q)
+	naiveCamTolerance float64
+}
+
+// Create a new path control
+func newPathControl(mode PathControlMode, tolerance float64, naiveCamTolerance float64) *PathControl {
+	return &PathControl{mode: mode, tolerance: tolerance, naiveCamTolerance: naiveCamTolerance}
+}
+
+// Get the mode
+func (p *PathControl) getMode() PathControlMode {
+	return p.mode
+}
+
+// Get the blending tolerance
+func (p *PathControl) getTolerance() float64 {
+	return p.tolerance
+}
+
+// Get the naive CAM tolerance
+func (p *PathControl) getNaiveCamTolerance() float64 {
+	return p.naiveCamTolerance
+}
+
+// The path control mode applies to the following junctions
+func (p *PathControl) requiresFullStop() bool {
+	return false
+}
+
+// Return a string representation of the path control
+func (p *PathControl) String() string {
+	if p.mode == PathExactStop {
+		return "Path:        Exact stop"
+	}
+	return fmt.Sprintf("Path:        Blending  P: %7.3f  Q: %7.3f", p.tolerance, p.naiveCamTolerance)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Get the maximum velocity at the junction of the two movements of a program
+func getCornerVelocity(lines []string) float64 {
+	planner := planLines(defaultMachineConfiguration(), lines)
+	movements := planner.commandList.GetMovementList()
+	return planner.getMaxCornerVelocity(movements[0], movements[1], planner.getPathControlList()[0])
+}
+
+func TestPathControlExactStop(t *testing.T) {
+	for _, code := range []string{"G61", "G61.1"} {
+		if velocity := getCornerVelocity([]string{code, "G1 X10 F600", "X20 Y0.1"}); velocity != 0 {
+			t.Errorf("%s: got a junction velocity of %g, expected 0", code, velocity)
+		}
+	}
+}
+
+func TestPathControlBlendingTolerance(t *testing.T) {
+	corner := []string{"G1 X10 F600", "Y10"}
+
+	narrow := getCornerVelocity(append([]string{"G64 P0.01"}, corner...))
+	wide := getCornerVelocity(append([]string{"G64 P0.5"}, corner...))
+	if narrow <= 0 || wide <= narrow || math.IsInf(wide, 1) {
+		t.Fatalf("got junction velocities %g for P0.01 and %g for P0.5, expected 0 < P0.01 < P0.5", narrow, wide)
+	}
+
+	// The path control is modal, the first junctions use the machine tolerance
+	if velocity := getCornerVelocity(corner); velocity != getCornerVelocity(append([]string{"G64"}, corner...)) {
+		t.Errorf("got a junction velocity of %g without G64, expected the G64 one", velocity)
+	}
+}
+
+func TestPathControlNaiveCam(t *testing.T) {
+	// The corner deviates by 0.05 from the line joining the outer points
+	lines := []string{"G1 X10 F600", "X20 Y0.1"}
+
+	if velocity := getCornerVelocity(append([]string{"G64 P0.01 Q0.1"}, lines...)); !math.IsInf(velocity, 1) {
+		t.Errorf("got a junction velocity of %g within Q, expected no limit", velocity)
+	}
+	if velocity := getCornerVelocity(append([]string{"G64 P0.01 Q0.01"}, lines...)); math.IsInf(velocity, 1) {
+		t.Errorf("got no junction velocity limit outside Q")
+	}
+}
+
+func TestPathControlIgnoresCycleWords(t *testing.T) {
+	// The P and Q carried by an active canned cycle are not tolerances
+	planner := planLines(defaultMachineConfiguration(), []string{"G0 Z5", "G83 X1 Z-1 R1 Q0.5 P0.5 F100", "G64"})
+
+	controls := getCommands[*PathControl](planner)
+	if len(controls) != 1 {
+		t.Fatalf("got path controls %v, expected 1", controls)
+	}
+	checkFloat(t, "tolerance", controls[0].getTolerance(), defaultMachineConfiguration().path_deviation_tolerance)
+	checkFloat(t, "naive CAM tolerance", controls[0].getNaiveCamTolerance(), 0)
+}
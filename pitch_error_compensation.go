@@ -0,0 +1,36 @@
+package main
+
+import "fmt"
+
+// Correct the leadscrew pitch error. The correction changes linearly along
+// the following movement from the previous value to reach the given value at
+// a fraction of the movement.
+type PitchErrorCompensation struct {
+	correction Vector3d
+	fraction   float64
+}
+
+// Create a new pitch error compensation
+func newPitchErrorCompensation(correction Vector3d, fraction float64) *PitchErrorCompensation {
+	return &PitchErrorCompensation{correction: correction, fraction: fraction}
+}
+
+// Get the correction reached at the fraction of the following movement
+func (p *PitchErrorCompensation) getCorrection() Vector3d {
+	return p.correction
+}
+
+// Get the fraction of the following movement at which the correction is reached
+func (p *PitchErrorCompensation) getFraction() float64 {
+	return p.fraction
+}
+
+// The compensation is executed while moving
+func (p *PitchErrorCompensation) requiresFullStop() bool {
+	return false
+}
+
+// Return a string representation of the pitch error compensation
+func (p *PitchErrorCompensation) String() string {
+	return fmt.Sprintf("Pitch error: %7.3f at %3.0f%% of next move", p.correction, p.fraction*100)
+}
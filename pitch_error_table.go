@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Leadscrew pitch error table giving the correction to apply at positions
+// along an axis. The correction is interpolated between the positions and
+// kept constant past the ends of the table.
+type PitchErrorTable struct {
+	positions   []float64
+	corrections []float64
+}
+
+// Create a new pitch error table, the positions do not need to be sorted but
+// each must have one correction
+func newPitchErrorTable(positions []float64, corrections []float64) (*PitchErrorTable, error) {
+	if len(positions) != len(corrections) {
+		return nil, fmt.Errorf("the pitch error table has %d positions and %d corrections", len(positions), len(corrections))
+	}
+
+	table := &PitchErrorTable{}
+	order := make([]int, len(positions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return positions[order[a]] < positions[order[b]] })
+
+	for _, i := range order {
+		if n := len(table.positions); n > 0 && table.positions[n-1] == positions[i] {
+			return nil, fmt.Errorf("the pitch error table has the position %g more than once", positions[i])
+		}
+		table.positions = append(table.positions, positions[i])
+		table.corrections = append(table.corrections, corrections[i])
+	}
+
+	return table, nil
+}
+
+// Get the correction at a position
+func (t *PitchErrorTable) getCorrection(position float64) float64 {
+	if len(t.positions) == 0 {
+		return 0
+	}
+	if position <= t.positions[0] {
+		return t.corrections[0]
+	}
+
+	for i := 1; i < len(t.positions); i++ {
+		if position <= t.positions[i] {
+			ratio := (position - t.positions[i-1]) / (t.positions[i] - t.positions[i-1])
+			return t.corrections[i-1] + ratio*(t.corrections[i]-t.corrections[i-1])
+		}
+	}
+
+	return t.corrections[len(t.corrections)-1]
+}
+
+// Get the fractions of a straight motion between two positions of the axis
+// where it crosses a position of the table
+func (t *PitchErrorTable) getCrossings(from float64, to float64) []float64 {
+	var fractions []float64
+	if from == to {
+		return fractions
+	}
+	for _, position := range t.positions {
+		if fraction := (position - from) / (to - from); fraction > 0 && fraction < 1 {
+			fractions = append(fractions, fraction)
+		}
+	}
+	return fractions
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestPitchErrorTableInterpolation(t *testing.T) {
+	// The positions are sorted with their corrections
+	table, err := newPitchErrorTable([]float64{100, 0, 50}, []float64{0.02, 0, -0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, point := range [][2]float64{{0, 0}, {25, -0.005}, {50, -0.01}, {75, 0.005}, {100, 0.02}, {-10, 0}, {150, 0.02}} {
+		checkFloat(t, "correction", table.getCorrection(point[0]), point[1])
+	}
+}
+
+func TestPitchErrorTableErrors(t *testing.T) {
+	if _, err := newPitchErrorTable([]float64{0, 50}, []float64{0}); err == nil {
+		t.Errorf("a table without a correction for each position is accepted")
+	}
+	if _, err := newPitchErrorTable([]float64{0, 50, 0}, []float64{0, 0.01, 0.02}); err == nil {
+		t.Errorf("a table with a repeated position is accepted")
+	}
+}
+
+func TestPitchErrorTableCrossings(t *testing.T) {
+	table, _ := newPitchErrorTable([]float64{0, 10, 20}, []float64{0, 0.1, 0})
+
+	fractions := table.getCrossings(20, -20)
+	if len(fractions) != 2 {
+		t.Fatalf("got crossings %v, expected 2", fractions)
+	}
+	// In the order of the table, the ends of the motion are not crossings
+	checkFloat(t, "crossing of X0", fractions[0], 0.5)
+	checkFloat(t, "crossing of X10", fractions[1], 0.25)
+	if fractions := table.getCrossings(5, 5); len(fractions) != 0 {
+		t.Errorf("got crossings %v without motion", fractions)
+	}
+}
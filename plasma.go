@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Turn the torch on with the pierce sequence, or off
+func (m *MotionPlanner) setTorch(on bool, feedrate float64) {
+	torch := m.machine_configuration.plasmaTorch
+	if on == m.torchOn {
+		return
+	}
+	m.torchOn = on
+
+	if !on {
+		m.commandList.addCommand(newTorchCommand(false))
+		return
+	}
+
+	// Pierce above the plate and go down to the cut height once through
+	position := m.commandList.getPreviousPosition()
+	m.cycleRapid(Vector3d{X: position.X, Y: position.Y, Z: torch.pierceHeight})
+	m.commandList.addCommand(newTorchCommand(true))
+	m.cycleDwell(torch.pierceDelay)
+
+	cut := Vector3d{X: position.X, Y: position.Y, Z: torch.cutHeight}
+	if feedrate > 0 {
+		m.cycleFeed(cut, feedrate)
+	} else {
+		m.cycleRapid(cut)
+	}
+}
+
+// Keep the movements without a Z word at the cut height while the torch is
+// on, the height control corrects Z from there
+func (m *MotionPlanner) holdTorchHeight(movement Movement, command GCodeCommand) {
+	if !m.torchOn || command.isOnLine("Z") {
+		return
+	}
+	end := movement.getEndPosition()
+	end.Z = m.machine_configuration.plasmaTorch.cutHeight
+	movement.setEndPosition(end)
+}
+
+// Get the distances along a movement between which the height control is
+// active. It is held while the planned velocity is below the threshold, at
+// the start and end of the movement.
+func (m *MotionPlanner) getHeightControlRange(movement Movement) (float64, float64) {
+	torch := m.machine_configuration.plasmaTorch
+	threshold := torch.velocityThreshold * movement.getGcodeVelocity()
+	acceleration := m.getMaxAcceleration(movement)
+	length := movement.getLength()
+
+	if movement.getTargetVelocity() < threshold {
+		return length, length
+	}
+
+	start := 0.0
+	if movement.getStartVelocity() < threshold {
+		start = (threshold*threshold - math.Pow(movement.getStartVelocity(), 2)) / (2 * acceleration)
+	}
+	end := length
+	if movement.getEndVelocity() < threshold {
+		end -= (threshold*threshold - math.Pow(movement.getEndVelocity(), 2)) / (2 * acceleration)
+	}
+
+	if start >= end {
+		return length, length
+	}
+	return start, end
+}
+
+// Get the planned velocity at a distance along a movement, accelerating from
+// the start velocity and decelerating to the end velocity
+func (m *MotionPlanner) getVelocityAt(movement Movement, distance float64) float64 {
+	acceleration := m.getMaxAcceleration(movement)
+	velocity := math.Min(movement.getTargetVelocity(), math.Sqrt(math.Pow(movement.getStartVelocity(), 2)+2*acceleration*distance))
+	return math.Min(velocity, math.Sqrt(math.Pow(movement.getEndVelocity(), 2)+2*acceleration*math.Max(0, movement.getLength()-distance)))
+}
+
+// Return a string representation of the distances along a movement where the height control is active
+func (m *MotionPlanner) heightControlString(movement Movement) string {
+	start, end := m.getHeightControlRange(movement)
+	if start >= end {
+		return "THC: Hold"
+	}
+	return fmt.Sprintf("THC: %7.3f -> %7.3f", start, end)
+}
@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+// Create a machine configuration with a plasma torch piercing at 3.8 for 0.5 s
+// and cutting at 1.5. The height control holds 100 V, moving 0.01 per volt of
+// error up to 1, above half the programmed velocity.
+func newPlasmaConfiguration() *MachineConfiguration {
+	torch := newPlasmaTorch(3.8, 0.5, 1.5)
+	torch.setHeightControl(100, 0.01, 1, 0.5)
+	configuration := defaultMachineConfiguration()
+	configuration.setPlasmaTorch(torch)
+	return configuration
+}
+
+func TestPlasmaPierceSequence(t *testing.T) {
+	planner := planLines(newPlasmaConfiguration(), []string{"G1 X10 Y10 F1200", "M3", "G1 X50", "G1 X60 Z0.5", "M5"})
+
+	// The torch goes down to the cut height at the modal feed, the cut holds
+	// it unless Z is programmed
+	checkEndPositions(t, planner, []Vector3d{
+		{X: 10, Y: 10}, {X: 10, Y: 10, Z: 3.8}, {X: 10, Y: 10, Z: 1.5},
+		{X: 50, Y: 10, Z: 1.5}, {X: 60, Y: 10, Z: 0.5},
+	})
+	checkFloat(t, "cut height feed", planner.commandList.GetMovementList()[2].getGcodeVelocity(), 20)
+
+	torches := getCommands[*TorchCommand](planner)
+	if len(torches) != 2 || !torches[0].isOn() || torches[1].isOn() {
+		t.Errorf("got torch commands %v, expected on and off", torches)
+	}
+	if dwells := getCommands[*Dwell](planner); len(dwells) != 1 || dwells[0].getDuration() != 0.5 {
+		t.Errorf("got dwells %v, expected the pierce delay", dwells)
+	}
+}
+
+// Run a cut along X from X0 to X10 at the cut height, at 20 units/s from end to end
+func runTestCut(t *testing.T, arcVoltage float64) (*Machine, *McuSimulator) {
+	t.Helper()
+	configuration := newPlasmaConfiguration()
+	simulator := newMcuSimulator(nil)
+	simulator.setArcVoltage(arcVoltage)
+	machine := newMachine(configuration, simulator)
+	if err := machine.moveTo(Vector3d{Z: 1.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	movement := newLinearMovement(Vector3d{X: 10, Z: 1.5}, 20)
+	movement.setStartPosition(Vector3d{Z: 1.5})
+	movement.setEndVelocity(20)
+
+	if err := machine.runCut(newMotionPlanner(configuration), movement, newTorchHeightController(configuration.plasmaTorch)); err != nil {
+		t.Fatal(err)
+	}
+	if machine.getState() != StateIdle {
+		t.Errorf("got %s, expected idle after the cut", machine.getState())
+	}
+	return machine, simulator
+}
+
+func TestPlasmaHeightControl(t *testing.T) {
+	// The arc is 20 V too long, the torch goes down by 0.2 at each reading
+	// until the largest correction
+	machine, simulator := runTestCut(t, 120)
+	checkVector(t, "machine position", machine.getPosition(), Vector3d{X: 10, Z: 0.5})
+	checkVector(t, "tool position", simulator.getToolPosition(), Vector3d{X: 10, Z: 0.5})
+}
+
+func TestPlasmaHeightControlWithoutArc(t *testing.T) {
+	// Without the arc the correction is held
+	machine, _ := runTestCut(t, 0)
+	checkVector(t, "machine position", machine.getPosition(), Vector3d{X: 10, Z: 1.5})
+}
+
+func TestPlasmaHeightControlHeldWhileSlow(t *testing.T) {
+	planner := newMotionPlanner(newPlasmaConfiguration())
+	movement := newLinearMovement(Vector3d{X: 100}, 20)
+	movement.setStartVelocity(0)
+
+	// Accelerating from a stop, the height control starts at half the velocity
+	start, end := planner.getHeightControlRange(movement)
+	checkFloat(t, "velocity at the start of the height control", planner.getVelocityAt(movement, start), 10)
+	checkFloat(t, "velocity at the end of the height control", planner.getVelocityAt(movement, end), 10)
+	checkFloat(t, "velocity at the start", planner.getVelocityAt(movement, 0), 0)
+}
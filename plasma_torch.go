@@ -0,0 +1,34 @@
+package main
+
+// Configuration of a plasma torch and of its height control
+type PlasmaTorch struct {
+	// The torch pierces at the pierce height, waits for the pierce delay in
+	// seconds and moves down to the cut height
+	pierceHeight float64
+	pierceDelay  float64
+	cutHeight    float64
+
+	// Arc voltage held by the height control and Z correction per volt of error
+	targetVoltage float64
+	gain          float64
+
+	// Largest Z correction of the height control
+	maxCorrection float64
+
+	// The height control holds its correction below this fraction of the
+	// programmed velocity, so the torch does not dive in the corners
+	velocityThreshold float64
+}
+
+// Create a new plasma torch configuration
+func newPlasmaTorch(pierceHeight float64, pierceDelay float64, cutHeight float64) *PlasmaTorch {
+	return &PlasmaTorch{pierceHeight: pierceHeight, pierceDelay: pierceDelay, cutHeight: cutHeight}
+}
+
+// Set the height control, the velocity threshold is a fraction of the programmed velocity
+func (p *PlasmaTorch) setHeightControl(targetVoltage float64, gain float64, maxCorrection float64, velocityThreshold float64) {
+	p.targetVoltage = targetVoltage
+	p.gain = gain
+	p.maxCorrection = maxCorrection
+	p.velocityThreshold = velocityThreshold
+}
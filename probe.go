@@ -0,0 +1,131 @@
+package main
+
+// Probe type enum (ProbeToward, ProbeTowardNoError, ProbeAway, ProbeAwayNoError)
+type ProbeType int
+
+const (
+	ProbeToward ProbeType = iota
+	ProbeTowardNoError
+	ProbeAway
+	ProbeAwayNoError
+)
+
+// Get the probe type of a G38.x command
+func probeTypeFromCommand(command string) (ProbeType, bool) {
+	switch command {
+	case "G38.2":
+		return ProbeToward, true
+	case "G38.3":
+		return ProbeTowardNoError, true
+	case "G38.4":
+		return ProbeAway, true
+	case "G38.5":
+		return ProbeAwayNoError, true
+	}
+	return ProbeToward, false
+}
+
+// Check if the probe moves toward the workpiece and stops on contact, or away and stops on loss of contact
+func (p ProbeType) isToward() bool {
+	return p == ProbeToward || p == ProbeTowardNoError
+}
+
+// Check if the probe fails when it is not triggered
+func (p ProbeType) failsWithoutTrigger() bool {
+	return p == ProbeToward || p == ProbeAway
+}
+
+// Return the G-code of the probe type
+func (p ProbeType) String() string {
+	switch p {
+	case ProbeTowardNoError:
+		return "G38.3"
+	case ProbeAway:
+		return "G38.4"
+	case ProbeAwayNoError:
+		return "G38.5"
+	}
+	return "G38.2"
+}
+
+// Stop the previous movement on the probe trigger (G38.2, G38.3, G38.4, G38.5)
+type Probe struct {
+	probeType ProbeType
+}
+
+// Create a new probe command
+func newProbe(probeType ProbeType) *Probe {
+	return &Probe{probeType: probeType}
+}
+
+// Get the probe type
+func (p *Probe) getProbeType() ProbeType {
+	return p.probeType
+}
+
+// The probing movement ends where the probe triggers, the next movements start from there
+func (p *Probe) requiresFullStop() bool {
+	return true
+}
+
+// Return a string representation of the probe
+func (p *Probe) String() string {
+	if p.probeType.isToward() {
+		return "Probe:       " + p.probeType.String() + "  Stop on contact"
+	}
+	return "Probe:       " + p.probeType.String() + "  Stop on loss of contact"
+}
+
+// Plan the commands up to the first probe and return the probe and the
+// commands after it. The movements after a probe start where it stops, which
+// is only known once it ran, so they are planned after continueFrom.
+func (m *MotionPlanner) planUntilProbe(gcodeList []GCodeCommand) []GCodeCommand {
+	for i, gcodeLine := range gcodeList {
+		if _, ok := probeTypeFromCommand(gcodeLine.command); ok {
+			return gcodeList[i:]
+		}
+		m.fromParsedGcode(gcodeList[i : i+1])
+	}
+	return nil
+}
+
+// Continue the planning from where a probe stopped, in work coordinates. The
+// axes stay there until a line gives their position.
+func (m *MotionPlanner) continueFrom(position Vector3d) {
+	m.commandList.previous_position = m.commandList.previous_position.withCartesian(position)
+	m.holdAxes(XAxis, YAxis, ZAxis)
+}
+
+// Keep axes where the planner moved them until a line gives their position
+func (m *MotionPlanner) holdAxes(axes ...Axis) {
+	if m.heldAxes == nil {
+		m.heldAxes = make(map[string]bool)
+	}
+	for _, axis := range axes {
+		m.heldAxes[axis.String()] = true
+	}
+}
+
+// Replace the axis words carried from the lines before a probe or a reference
+// return by the position the axes were moved to
+func (m *MotionPlanner) followHeldAxes(gcodeLine GCodeCommand) GCodeCommand {
+	if len(m.heldAxes) == 0 {
+		return gcodeLine
+	}
+
+	params := make(map[string]float64)
+	for key, val := range gcodeLine.params {
+		params[key] = val
+	}
+	for axis := XAxis; axis <= WAxis; axis++ {
+		name := axis.String()
+		if gcodeLine.isOnLine(name) {
+			delete(m.heldAxes, name)
+		} else if _, ok := params[name]; ok && m.heldAxes[name] {
+			params[name] = m.commandList.previous_position.get(axis)
+		}
+	}
+
+	gcodeLine.params = params
+	return gcodeLine
+}
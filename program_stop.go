@@ -0,0 +1,44 @@
+package main
+
+// Program stop type enum (ProgramPause, ProgramOptionalPause, ProgramEnd, ProgramEndRewind)
+type ProgramStopType int
+
+const (
+	ProgramPause ProgramStopType = iota
+	ProgramOptionalPause
+	ProgramEnd
+	ProgramEndRewind
+)
+
+// Pause or end the program (M0, M1, M2, M30)
+type ProgramStop struct {
+	stopType ProgramStopType
+}
+
+// Create a new program stop
+func newProgramStop(stopType ProgramStopType) *ProgramStop {
+	return &ProgramStop{stopType: stopType}
+}
+
+// Get the stop type
+func (p *ProgramStop) getStopType() ProgramStopType {
+	return p.stopType
+}
+
+// The machine must be stopped to pause or end the program
+func (p *ProgramStop) requiresFullStop() bool {
+	return true
+}
+
+// Return a string representation of the program stop
+func (p *ProgramStop) String() string {
+	switch p.stopType {
+	case ProgramOptionalPause:
+		return "Program:     Optional pause"
+	case ProgramEnd:
+		return "Program:     End"
+	case ProgramEndRewind:
+		return "Program:     End and rewind"
+	}
+	return "Program:     Pause"
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Return the axes to their reference position through an intermediate point
+// (G28), or home them on the dialects where G28 homes
+type ReferenceReturn struct {
+	axes         []string
+	intermediate map[string]float64
+	homing       bool
+}
+
+// Create a new reference return from the axis words of its line. Without
+// axis words all the axes return.
+func newReferenceReturn(params map[string]float64, homing bool) *ReferenceReturn {
+	r := &ReferenceReturn{intermediate: make(map[string]float64), homing: homing}
+	for _, axis := range []string{"X", "Y", "Z", "A", "B", "C", "U", "V", "W"} {
+		if val, ok := params[axis]; ok {
+			r.axes = append(r.axes, axis)
+			r.intermediate[axis] = val
+		}
+	}
+	return r
+}
+
+// Get the axes returning, none for all of them
+func (r *ReferenceReturn) getAxes() []string {
+	return r.axes
+}
+
+// Get the intermediate point of an axis, in the distance mode of the program
+func (r *ReferenceReturn) getIntermediate(axis string) (float64, bool) {
+	val, ok := r.intermediate[axis]
+	return val, ok && !r.homing
+}
+
+// Check if an axis returns, all of them return without axis words
+func (r *ReferenceReturn) returns(axis string) bool {
+	if len(r.axes) == 0 {
+		return true
+	}
+	for _, returning := range r.axes {
+		if returning == axis {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the axes are homed instead of moved to their reference position
+func (r *ReferenceReturn) isHoming() bool {
+	return r.homing
+}
+
+// The machine must be stopped to return the axes
+func (r *ReferenceReturn) requiresFullStop() bool {
+	return true
+}
+
+// Return a string representation of the reference return
+func (r *ReferenceReturn) String() string {
+	axes := "All"
+	if len(r.axes) > 0 {
+		axes = strings.Join(r.axes, " ")
+	}
+	if r.homing {
+		return fmt.Sprintf("Home:        %s", axes)
+	}
+	return fmt.Sprintf("Reference:   %s", axes)
+}
+
+// Return the axes to their reference position with rapids through the
+// intermediate point of the line, or home them. The following movements start
+// from the reference position. A G28 of an axis without a reference position
+// is left out.
+func (m *MotionPlanner) returnToReference(command GCodeCommand) {
+	referenceReturn := newReferenceReturn(command.params, command.command == "Home")
+
+	intermediate := m.commandList.previous_position
+	reference := m.commandList.previous_position
+	var returning []Axis
+	for axis := XAxis; axis <= WAxis; axis++ {
+		if !referenceReturn.returns(axis.String()) {
+			continue
+		}
+		position, ok := m.machine_configuration.getReferencePosition(axis)
+		if !ok && !referenceReturn.isHoming() {
+			// Without axis words only the axes with a reference return
+			if len(referenceReturn.getAxes()) == 0 {
+				continue
+			}
+			m.errors = append(m.errors, fmt.Errorf("G28 of %s without a reference position", axis))
+			return
+		}
+		if val, ok := referenceReturn.getIntermediate(axis.String()); ok {
+			if m.incrementalDistance {
+				val += intermediate.get(axis)
+			}
+			intermediate = intermediate.with(axis, val)
+		}
+		reference = reference.with(axis, position)
+		returning = append(returning, axis)
+	}
+	if len(returning) == 0 && !referenceReturn.isHoming() {
+		m.errors = append(m.errors, errors.New("G28 without a reference position"))
+		return
+	}
+	m.holdAxes(returning...)
+
+	m.commandList.addCommand(referenceReturn)
+	if referenceReturn.isHoming() {
+		m.commandList.previous_position = reference
+		return
+	}
+	m.rapidToAxisPosition(intermediate)
+	m.rapidToAxisPosition(reference)
+}
+
+// Rapid to a position of all the axes, skipping moves that would not change the position
+func (m *MotionPlanner) rapidToAxisPosition(position AxisPosition) {
+	if position.equals(m.commandList.previous_position) {
+		return
+	}
+	movement := newRapidMovement(position.cartesian(), m.machine_configuration.getRapidVelocity())
+	movement.setEndAxisPosition(position)
+	m.commandList.addMovement(movement)
+}
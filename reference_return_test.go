@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+func TestReferenceReturnThroughIntermediatePoint(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setReferencePosition(newAxisPosition(Vector3d{}))
+	planner := planLines(configuration, []string{"G0 X10 Y10 Z5", "G28 Z20", "G1 X5 F100"})
+
+	// Only Z returns, and stays at the reference until a line moves it
+	checkEndPositions(t, planner, []Vector3d{{X: 10, Y: 10, Z: 5}, {X: 10, Y: 10, Z: 20}, {X: 10, Y: 10}, {X: 5, Y: 10}})
+	references := getCommands[*ReferenceReturn](planner)
+	if len(references) != 1 || len(references[0].getAxes()) != 1 || references[0].getAxes()[0] != "Z" {
+		t.Errorf("got reference returns %v, expected one of Z", references)
+	}
+}
+
+func TestReferenceReturnOfAllAxes(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setReferencePosition(newAxisPosition(Vector3d{X: 1, Y: 2, Z: 3}))
+	planner := planLines(configuration, []string{"G0 X10 Y10 Z5", "G28", "G0 X20"})
+
+	checkEndPositions(t, planner, []Vector3d{{X: 10, Y: 10, Z: 5}, {X: 1, Y: 2, Z: 3}, {X: 20, Y: 2, Z: 3}})
+}
+
+func TestReferenceReturnThroughIncrementalPoint(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setReferencePosition(newAxisPosition(Vector3d{Z: 50}))
+
+	// The G91 of the line applies to the intermediate point, Z0 stays in place
+	planner := planLines(configuration, []string{"G0 X10 Y10 Z5", "G28 G91 Z0.", "G90", "G28 G91 Z2", "G90"})
+	checkEndPositions(t, planner, []Vector3d{{X: 10, Y: 10, Z: 5}, {X: 10, Y: 10, Z: 50}, {X: 10, Y: 10, Z: 52}, {X: 10, Y: 10, Z: 50}})
+}
+
+func TestReferenceReturnDefaultsToHome(t *testing.T) {
+	configuration := defaultMachineConfiguration()
+	configuration.setHomingAxes([]*HomingAxis{newHomingAxis(ZAxis, 0, 1, 50, 5, 2, 120, 300, []int{2})})
+	planner := planLines(configuration, []string{"G0 X10 Y10 Z5", "G28", "G28 X0"})
+
+	// Only Z has a home position, G28 of X has nowhere to return to
+	checkEndPositions(t, planner, []Vector3d{{X: 10, Y: 10, Z: 5}, {X: 10, Y: 10, Z: 120}})
+	if errors := planner.getErrors(); len(errors) != 1 {
+		t.Errorf("got errors %v, expected the G28 of X to be left out", errors)
+	}
+
+	planner = planLines(defaultMachineConfiguration(), []string{"G0 X10 Y10 Z5", "G28"})
+	checkEndPositions(t, planner, []Vector3d{{X: 10, Y: 10, Z: 5}})
+	if errors := planner.getErrors(); len(errors) != 1 {
+		t.Errorf("got errors %v, expected the G28 without a reference to be left out", errors)
+	}
+}
+
+func TestHomingContinuesFromTheReference(t *testing.T) {
+	planner := newMotionPlanner(defaultMachineConfiguration())
+	planner.fromParsedGcode(parseLines(newMarlinDialect(), []string{"G0 X10 Y10 Z5", "G28", "G1 X5 F100"}))
+
+	// The machine homes the axes, the planner has no movement to add
+	checkEndPositions(t, planner, []Vector3d{{X: 10, Y: 10, Z: 5}, {X: 5}})
+	if references := getCommands[*ReferenceReturn](planner); len(references) != 1 || !references[0].isHoming() {
+		t.Errorf("got reference returns %v, expected homing", references)
+	}
+}
+
+func TestTemperatureIsNotASpindleSpeed(t *testing.T) {
+	planner := newMotionPlanner(defaultMachineConfiguration())
+	planner.fromParsedGcode(parseLines(newMarlinDialect(), []string{"M3 S1000", "M104 S200", "M5", "M3", "M140 S60", "M4"}))
+
+	var speeds []float64
+	for _, spindle := range getCommands[*SpindleCommand](planner) {
+		speeds = append(speeds, spindle.getSpeed())
+	}
+	expected := []float64{1000, 0, 1000, 1000}
+	if len(speeds) != len(expected) {
+		t.Fatalf("got spindle speeds %v, expected %v", speeds, expected)
+	}
+	for i := range expected {
+		checkFloat(t, "spindle speed", speeds[i], expected[i])
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import "fmt"
+
+// Spindle direction enum (SpindleOff, SpindleClockwise, SpindleCounterClockwise)
+type SpindleDirection int
+
+const (
+	SpindleOff SpindleDirection = iota
+	SpindleClockwise
+	SpindleCounterClockwise
+)
+
+// Start or stop the spindle (M3, M4, M5)
+type SpindleCommand struct {
+	direction SpindleDirection
+	speed     float64
+
+	// In laser mode, M3 is a constant power, M4 a power following the velocity and S the power
+	laser bool
+}
+
+// Create a new spindle command, the speed is in rpm
+func newSpindleCommand(direction SpindleDirection, speed float64) *SpindleCommand {
+	return &SpindleCommand{direction: direction, speed: speed}
+}
+
+// Get the direction
+func (s *SpindleCommand) getDirection() SpindleDirection {
+	return s.direction
+}
+
+// Get the speed
+func (s *SpindleCommand) getSpeed() float64 {
+	return s.speed
+}
+
+// Check if the spindle is a laser
+func (s *SpindleCommand) isLaser() bool {
+	return s.laser
+}
+
+// Set if the spindle is a laser
+func (s *SpindleCommand) setLaser(laser bool) {
+	s.laser = laser
+}
+
+// The spindle must reach its speed before cutting, a laser changes power on the fly
+func (s *SpindleCommand) requiresFullStop() bool {
+	return !s.laser
+}
+
+// Return a string representation of the spindle command
+func (s *SpindleCommand) String() string {
+	if s.laser {
+		switch s.direction {
+		case SpindleClockwise:
+			return fmt.Sprintf("Laser:       Constant %7.0f", s.speed)
+		case SpindleCounterClockwise:
+			return fmt.Sprintf("Laser:       Dynamic  %7.0f", s.speed)
+		}
+		return "Laser:       Off"
+	}
+
+	switch s.direction {
+	case SpindleClockwise:
+		return fmt.Sprintf("Spindle:     CW  %7.0f rpm", s.speed)
+	case SpindleCounterClockwise:
+		return fmt.Sprintf("Spindle:     CCW %7.0f rpm", s.speed)
+	}
+	return "Spindle:     Off"
+}
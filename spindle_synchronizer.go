@@ -0,0 +1,37 @@
+package main
+
+import "math"
+
+// Follow the spindle encoder through a synchronized motion. The motion starts
+// on the next index of the spindle, so every pass of a thread starts at the
+// same angle.
+type SpindleSynchronizer struct {
+	motion        *SynchronizedMotion
+	startPosition float64
+	started       bool
+}
+
+// Create a new synchronizer for a motion
+func newSpindleSynchronizer(motion *SynchronizedMotion) *SpindleSynchronizer {
+	return &SpindleSynchronizer{motion: motion}
+}
+
+// Get the tool position for an encoder feedback, and if the motion is finished
+func (s *SpindleSynchronizer) update(feedback EncoderFeedbackMessage) (Vector3d, bool) {
+	if !s.started {
+		// Wait for the next index in the direction the spindle turns
+		if feedback.velocity >= 0 {
+			s.startPosition = math.Ceil(feedback.position)
+		} else {
+			s.startPosition = math.Floor(feedback.position)
+		}
+		s.started = true
+	}
+
+	revolutions := feedback.position - s.startPosition
+	if feedback.velocity < 0 {
+		revolutions = -revolutions
+	}
+
+	return s.motion.getPositionAt(revolutions)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Straight motion locked to the spindle position (G33, G33.1, G76). The tool
+// advances by the pitch for each spindle revolution, so its progress follows
+// the spindle encoder instead of the time.
+type SynchronizedMotion struct {
+	start_position Vector3d
+	end_position   Vector3d
+	pitch          float64
+}
+
+// Create a new synchronized motion, the pitch is the distance per revolution
+func newSynchronizedMotion(end_position Vector3d, pitch float64) *SynchronizedMotion {
+	return &SynchronizedMotion{end_position: end_position, pitch: pitch}
+}
+
+// Get the start position
+func (s *SynchronizedMotion) getStartPosition() Vector3d {
+	return s.start_position
+}
+
+// Set the start position
+func (s *SynchronizedMotion) setStartPosition(start_position Vector3d) {
+	s.start_position = start_position
+}
+
+// Get the end position
+func (s *SynchronizedMotion) getEndPosition() Vector3d {
+	return s.end_position
+}
+
+// Get the pitch
+func (s *SynchronizedMotion) getPitch() float64 {
+	return s.pitch
+}
+
+// Get the length of the motion
+func (s *SynchronizedMotion) getLength() float64 {
+	return s.end_position.subtract(s.start_position).length()
+}
+
+// Get the number of spindle revolutions the motion lasts
+func (s *SynchronizedMotion) getRevolutions() float64 {
+	if s.pitch <= 0 {
+		return 0
+	}
+	return s.getLength() / s.pitch
+}
+
+// Get the position after a number of spindle revolutions, and if the end is reached
+func (s *SynchronizedMotion) getPositionAt(revolutions float64) (Vector3d, bool) {
+	length := s.getLength()
+	distance := math.Max(0, revolutions*s.pitch)
+	if length == 0 || distance >= length {
+		return s.end_position, true
+	}
+	return s.start_position.Add(s.end_position.subtract(s.start_position).scale(distance / length)), false
+}
+
+// The spindle must be at speed and the motion waits for its index
+func (s *SynchronizedMotion) requiresFullStop() bool {
+	return true
+}
+
+// Return a string representation of the synchronized motion
+func (s *SynchronizedMotion) String() string {
+	return fmt.Sprintf("Synchronized: Pos: %7.3f -> %7.3f  Pitch: %7.3f /rev", s.start_position, s.end_position, s.pitch)
+}
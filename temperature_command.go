@@ -0,0 +1,56 @@
+package main
+
+import "fmt"
+
+// Heater type enum (HeaterHotend, HeaterBed)
+type HeaterType int
+
+const (
+	HeaterHotend HeaterType = iota
+	HeaterBed
+)
+
+// Set the target temperature of a heater (M104, M109, M140, M190), waiting
+// for it to be reached or not
+type TemperatureCommand struct {
+	heater      HeaterType
+	temperature float64
+	wait        bool
+}
+
+// Create a new temperature command
+func newTemperatureCommand(heater HeaterType, temperature float64, wait bool) *TemperatureCommand {
+	return &TemperatureCommand{heater: heater, temperature: temperature, wait: wait}
+}
+
+// Get the heater
+func (t *TemperatureCommand) getHeater() HeaterType {
+	return t.heater
+}
+
+// Get the target temperature, in degrees Celsius
+func (t *TemperatureCommand) getTemperature() float64 {
+	return t.temperature
+}
+
+// Check if the program waits for the temperature
+func (t *TemperatureCommand) isWaiting() bool {
+	return t.wait
+}
+
+// The machine waits standing still for the temperature to be reached
+func (t *TemperatureCommand) requiresFullStop() bool {
+	return t.wait
+}
+
+// Return a string representation of the temperature command
+func (t *TemperatureCommand) String() string {
+	heater := "Hotend"
+	if t.heater == HeaterBed {
+		heater = "Bed"
+	}
+	if t.wait {
+		return fmt.Sprintf("Temperature: %s %.0f  (wait)", heater, t.temperature)
+	}
+	return fmt.Sprintf("Temperature: %s %.0f", heater, t.temperature)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Check that the pitch of a synchronized motion advances the tool, the
+// motion would never end otherwise
+func checkPitch(command string, pitch float64) error {
+	if pitch <= 0 {
+		return fmt.Errorf("%s pitch of %g is not positive", command, pitch)
+	}
+	return nil
+}
+
+// Rigid tap to the programmed position and back (G33.1). The spindle reverses
+// at the bottom and the tool follows it out of the hole with the same pitch.
+func (m *MotionPlanner) rigidTap(gcodeLine GCodeCommand) error {
+	start := m.commandList.getPreviousPosition()
+	end := Vector3d{X: gcodeLine.params["X"], Y: gcodeLine.params["Y"], Z: gcodeLine.params["Z"]}
+	pitch := gcodeLine.params["K"]
+	if err := checkPitch(gcodeLine.command, pitch); err != nil {
+		return err
+	}
+	spindle := m.spindle
+
+	m.commandList.addSynchronizedMotion(newSynchronizedMotion(end, pitch))
+	m.setSpindle(reverseSpindle(spindle.direction), spindle.speed)
+	m.commandList.addSynchronizedMotion(newSynchronizedMotion(start, pitch))
+	m.setSpindle(spindle.direction, spindle.speed)
+	return nil
+}
+
+// Expand a threading cycle (G76) into synchronized passes. The position before
+// the cycle is the drive line, I offsets the thread peak from it in X and the
+// passes cut J, then J times n to the power 1/R deep, up to the K thread depth.
+// Q is the compound slide angle the passes start along and H the number of
+// spring passes at the full depth.
+func (m *MotionPlanner) expandThreadingCycle(gcodeLine GCodeCommand) error {
+	params := gcodeLine.params
+	drive := m.commandList.getPreviousPosition()
+
+	pitch := params["P"]
+	if err := checkPitch(gcodeLine.command, pitch); err != nil {
+		return err
+	}
+	end := params["Z"]
+	peak := drive.X + params["I"]
+	firstDepth := params["J"]
+	threadDepth := params["K"]
+	springPasses := int(params["H"])
+
+	degression, ok := params["R"]
+	if !ok || degression < 1 {
+		degression = 1
+	}
+
+	// The passes go deeper away from the drive line
+	inward := -1.0
+	if params["I"] > 0 {
+		inward = 1
+	}
+
+	// The compound infeed starts the deeper passes back along the thread
+	backward := -1.0
+	if end < drive.Z {
+		backward = 1
+	}
+	compound := math.Tan(params["Q"] * math.Pi / 180)
+
+	var depths []float64
+	if firstDepth > 0 && threadDepth > 0 {
+		for pass := 1; ; pass++ {
+			depth := math.Min(firstDepth*math.Pow(float64(pass), 1/degression), threadDepth)
+			depths = append(depths, depth)
+			if depth >= threadDepth {
+				break
+			}
+		}
+		for i := 0; i < springPasses; i++ {
+			depths = append(depths, threadDepth)
+		}
+	}
+
+	for _, depth := range depths {
+		x := peak + inward*depth
+		z := drive.Z + backward*depth*compound
+
+		m.cycleRapid(Vector3d{X: drive.X, Y: drive.Y, Z: z})
+		m.cycleRapid(Vector3d{X: x, Y: drive.Y, Z: z})
+		m.commandList.addSynchronizedMotion(newSynchronizedMotion(Vector3d{X: x, Y: drive.Y, Z: end}, pitch))
+		m.cycleRapid(Vector3d{X: drive.X, Y: drive.Y, Z: end})
+		m.cycleRapid(drive)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestRigidTap(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"M3 S500", "G0 X0 Y0 Z5", "G33.1 Z-10 K1.25"})
+
+	// The tool follows the spindle in and, once it reverses, out of the hole
+	motions := getCommands[*SynchronizedMotion](planner)
+	if len(motions) != 2 {
+		t.Fatalf("got synchronized motions %v, expected 2", motions)
+	}
+	checkVector(t, "tap bottom", motions[0].getEndPosition(), Vector3d{Z: -10})
+	checkVector(t, "tap return", motions[1].getEndPosition(), Vector3d{Z: 5})
+	checkFloat(t, "pitch", motions[1].getPitch(), 1.25)
+
+	var directions []SpindleDirection
+	for _, spindle := range getCommands[*SpindleCommand](planner) {
+		directions = append(directions, spindle.getDirection())
+	}
+	expected := []SpindleDirection{SpindleClockwise, SpindleCounterClockwise, SpindleClockwise}
+	if len(directions) != len(expected) || directions[1] != expected[1] || directions[2] != expected[2] {
+		t.Errorf("got spindle directions %v, expected %v", directions, expected)
+	}
+}
+
+func TestThreadingCyclePasses(t *testing.T) {
+	planner := planLines(defaultMachineConfiguration(), []string{"M3 S300", "G0 X10 Z5", "G76 Z-20 P1.5 I-1 J0.2 K0.6 H1"})
+
+	// The passes cut 0.2 deeper each time from the peak at X9, then the
+	// spring pass cuts the full depth again
+	motions := getCommands[*SynchronizedMotion](planner)
+	expected := []float64{8.8, 8.6, 8.4, 8.4}
+	if len(motions) != len(expected) {
+		t.Fatalf("got synchronized motions %v, expected %d passes", motions, len(expected))
+	}
+	for i, x := range expected {
+		checkVector(t, "pass end", motions[i].getEndPosition(), Vector3d{X: x, Z: -20})
+		checkFloat(t, "pitch", motions[i].getPitch(), 1.5)
+	}
+
+	// The cycle ends back on the drive line
+	positions := getEndPositions(planner)
+	checkVector(t, "cycle end", positions[len(positions)-1], Vector3d{X: 10, Z: 5})
+}
+
+func TestSynchronizedMotionsRequireAPitch(t *testing.T) {
+	commands, diagnostics := parseWithDiagnostics([]string{"M3 S500", "G33 Z-10", "G33.1 Z-10", "G76 Z-20 I-1 J0.2 K0.6"})
+	checkDiagnosticLines(t, diagnostics, 2, 3, 4)
+	if hasCommand(commands, "G33") || hasCommand(commands, "G33.1") || hasCommand(commands, "G76") {
+		t.Errorf("got commands %v, expected the motions without a pitch to be left out", commands)
+	}
+
+	// A pitch that does not advance the tool is left out of the plan
+	planner := planLines(defaultMachineConfiguration(), []string{"M3 S500", "G33 Z-10 K0", "G33.1 Z-10 K-1", "G76 Z-20 P0 I-1 J0.2 K0.6"})
+	if motions := getCommands[*SynchronizedMotion](planner); len(motions) != 0 {
+		t.Errorf("got synchronized motions %v, expected none", motions)
+	}
+	if errors := planner.getErrors(); len(errors) != 3 {
+		t.Errorf("got errors %v, expected one per motion", errors)
+	}
+}
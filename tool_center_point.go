@@ -0,0 +1,41 @@
+package main
+
+import "fmt"
+
+// Enable or disable the tool center point control (G43.4, G49). When enabled,
+// the movements give the position of the tool tip on the part and the
+// orientation of the tool, and the five axis kinematics give the joints.
+type ToolCenterPoint struct {
+	enabled bool
+
+	// Length of the tool given by the H word
+	toolLength float64
+}
+
+// Create a new tool center point control command
+func newToolCenterPoint(enabled bool, toolLength float64) *ToolCenterPoint {
+	return &ToolCenterPoint{enabled: enabled, toolLength: toolLength}
+}
+
+// Check if the tool center point control is enabled
+func (t *ToolCenterPoint) isEnabled() bool {
+	return t.enabled
+}
+
+// Get the length of the tool
+func (t *ToolCenterPoint) getToolLength() float64 {
+	return t.toolLength
+}
+
+// Switching the programming mode does not need the machine to stop
+func (t *ToolCenterPoint) requiresFullStop() bool {
+	return false
+}
+
+// Return a string representation of the tool center point control
+func (t *ToolCenterPoint) String() string {
+	if t.enabled {
+		return fmt.Sprintf("TCP:         On, tool length %g", t.toolLength)
+	}
+	return "TCP:         Off"
+}
@@ -0,0 +1,28 @@
+package main
+
+import "fmt"
+
+// Change the tool (M6)
+type ToolChange struct {
+	tool int
+}
+
+// Create a new tool change
+func newToolChange(tool int) *ToolChange {
+	return &ToolChange{tool: tool}
+}
+
+// Get the tool number
+func (t *ToolChange) getTool() int {
+	return t.tool
+}
+
+// The machine must be stopped to change the tool
+func (t *ToolChange) requiresFullStop() bool {
+	return true
+}
+
+// Return a string representation of the tool change
+func (t *ToolChange) String() string {
+	return fmt.Sprintf("Tool change: T%d", t.tool)
+}
@@ -0,0 +1,29 @@
+package main
+
+// Turn the plasma torch on or off (M3, M5 with a plasma torch)
+type TorchCommand struct {
+	on bool
+}
+
+// Create a new torch command
+func newTorchCommand(on bool) *TorchCommand {
+	return &TorchCommand{on: on}
+}
+
+// Get the torch state
+func (t *TorchCommand) isOn() bool {
+	return t.on
+}
+
+// The torch pierces standing still, and turns off with the end of the cut
+func (t *TorchCommand) requiresFullStop() bool {
+	return t.on
+}
+
+// Return a string representation of the torch command
+func (t *TorchCommand) String() string {
+	if t.on {
+		return "Torch:       On"
+	}
+	return "Torch:       Off"
+}
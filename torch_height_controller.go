@@ -0,0 +1,43 @@
+package main
+
+import "math"
+
+// Closed loop correction of the torch height from the arc voltage. A higher
+// voltage means a longer arc, so the torch moves down, and the other way around.
+type TorchHeightController struct {
+	torch      *PlasmaTorch
+	correction float64
+}
+
+// Create a new torch height controller
+func newTorchHeightController(torch *PlasmaTorch) *TorchHeightController {
+	return &TorchHeightController{torch: torch}
+}
+
+// Get the Z correction
+func (t *TorchHeightController) getCorrection() float64 {
+	return t.correction
+}
+
+// Clear the correction, at the start of each cut
+func (t *TorchHeightController) reset() {
+	t.correction = 0
+}
+
+// Check if the height control is active at a velocity
+func (t *TorchHeightController) isActive(velocity float64, programmedVelocity float64) bool {
+	return velocity >= t.torch.velocityThreshold*programmedVelocity
+}
+
+// Update the Z correction from an arc voltage message. The correction is held
+// when the arc is lost or when slowing down in the corners.
+func (t *TorchHeightController) update(message ArcVoltageMessage, velocity float64, programmedVelocity float64) float64 {
+	if !message.arcOk || !t.isActive(velocity, programmedVelocity) {
+		return t.correction
+	}
+
+	t.correction -= t.torch.gain * (message.voltage - t.torch.targetVoltage)
+	t.correction = math.Max(-t.torch.maxCorrection, math.Min(t.correction, t.torch.maxCorrection))
+
+	return t.correction
+}
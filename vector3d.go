@@ -5,13 +5,22 @@ import (
 	"math"
 )
 
-// Axis enum containing the three axis (AxisX, AxisY, AxisZ)
+// Axis enum containing the three axis (AxisX, AxisY, AxisZ) followed by the
+// auxiliary axes (AAxis, BAxis, CAxis are rotary, UAxis, VAxis, WAxis are
+// linear, EAxis is the extruder)
 type Axis int
 
 const (
 	XAxis Axis = iota
 	YAxis
 	ZAxis
+	AAxis
+	BAxis
+	CAxis
+	UAxis
+	VAxis
+	WAxis
+	EAxis
 )
 
 // Vector 3d struct
@@ -55,6 +64,8 @@ func (v Vector3d) AngleWith(v2 Vector3d) float64 {
 
 	if ratio > 1 {
 		ratio = 1
+	} else if ratio < -1 {
+		ratio = -1
 	}
 
 	return math.Acos(ratio)
@@ -83,6 +94,21 @@ func (v Vector3d) Rotate90(axis Axis, clockwise bool) Vector3d {
 	return v
 }
 
+// Rotate by an angle in radians around the given axis, counter clockwise when looking down the axis
+func (v Vector3d) rotate(axis Axis, angle float64) Vector3d {
+	sin, cos := math.Sin(angle), math.Cos(angle)
+	switch axis {
+	case XAxis:
+		return Vector3d{X: v.X, Y: v.Y*cos - v.Z*sin, Z: v.Y*sin + v.Z*cos}
+	case YAxis:
+		return Vector3d{X: v.X*cos + v.Z*sin, Y: v.Y, Z: -v.X*sin + v.Z*cos}
+	case ZAxis:
+		return Vector3d{X: v.X*cos - v.Y*sin, Y: v.X*sin + v.Y*cos, Z: v.Z}
+	}
+
+	return v
+}
+
 func (v Vector3d) max() float64 {
 	return math.Max(math.Max(v.X, v.Y), v.Z)
 }
@@ -92,11 +118,43 @@ func (v Vector3d) Add(v2 Vector3d) Vector3d {
 	return Vector3d{X: v.X + v2.X, Y: v.Y + v2.Y, Z: v.Z + v2.Z}
 }
 
+// scale the vector by a factor
+func (v Vector3d) scale(factor float64) Vector3d {
+	return Vector3d{X: v.X * factor, Y: v.Y * factor, Z: v.Z * factor}
+}
+
+// Get the component along the given axis
+func (v Vector3d) component(axis Axis) float64 {
+	switch axis {
+	case XAxis:
+		return v.X
+	case YAxis:
+		return v.Y
+	}
+	return v.Z
+}
+
+// Project onto the plane perpendicular to the given axis
+func (v Vector3d) project(axis Axis) Vector3d {
+	switch axis {
+	case XAxis:
+		return Vector3d{X: 0, Y: v.Y, Z: v.Z}
+	case YAxis:
+		return Vector3d{X: v.X, Y: 0, Z: v.Z}
+	}
+	return Vector3d{X: v.X, Y: v.Y, Z: 0}
+}
+
 // subtract two vectors
 func (v Vector3d) subtract(v2 Vector3d) Vector3d {
 	return Vector3d{X: v.X - v2.X, Y: v.Y - v2.Y, Z: v.Z - v2.Z}
 }
 
+// Check that the components are neither infinite nor NaN
+func (v Vector3d) isFinite() bool {
+	return !math.IsNaN(v.X+v.Y+v.Z) && !math.IsInf(v.X+v.Y+v.Z, 0)
+}
+
 // Cross
 func (v Vector3d) Cross(v2 Vector3d) Vector3d {
 	return Vector3d{X: v.Y*v2.Z - v.Z*v2.Y, Y: v.Z*v2.X - v.X*v2.Z, Z: v.X*v2.Y - v.Y*v2.X}